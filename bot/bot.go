@@ -3,6 +3,8 @@ package bot
 import (
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"bt-bot/bot/callback_query"
@@ -21,9 +23,17 @@ type Bot struct {
 	bot *tgbotapi.BotAPI
 }
 
-// NewBot 创建新的 Bot 实例
-func NewBot(token string, debug bool) (*Bot, error) {
-	bot, err := tgbotapi.NewBotAPI(token)
+// NewBot 创建新的 Bot 实例，proxyURL 不为 nil 时 Bot API 请求经过代理
+func NewBot(token string, debug bool, timeout int, proxyURL *url.URL) (*Bot, error) {
+	client := &http.Client{}
+	if proxyURL != nil {
+		// http.Transport 原生支持 http、https 和 socks5 代理
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		client.Transport = transport
+	}
+
+	bot, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, client)
 	if err != nil {
 		return nil, fmt.Errorf("创建 bot 实例失败: %w", err)
 	}
@@ -31,7 +41,7 @@ func NewBot(token string, debug bool) (*Bot, error) {
 	bot_ := &Bot{
 		token:   token,
 		debug:   debug,
-		timeout: 60,
+		timeout: timeout,
 		bot:     bot,
 	}
 
@@ -49,34 +59,55 @@ func (b *Bot) Run() error {
 
 	log.Println("Bot 已启动，等待消息...")
 
+	// 恢复重启前未完成的下载任务
+	callback_query.ResumeDownloadJobs(b.bot)
+
 	// 处理更新
 	for update := range updates {
-		go func() {
-			// 处理回调查询（按钮点击）
-			if update.CallbackQuery != nil {
-				callback_query.CallbackQueryHandler(b.bot, &update)
-				return
-			}
-
-			if update.Message == nil {
-				command.StartCommand(b.bot, &update)
-				return
-			}
-
-			if containsMagnetLink(update.Message.Text) {
-				middleware.MagnetMiddleWare(command.MagnetCommand)(b.bot, &update)
-				return
-			}
-
-			// 处理命令
-			command.CommandHandler(b.bot, &update)
-		}()
+		go middleware.BanMiddleWare(handleUpdate)(b.bot, &update)
 	}
 
 	return nil
 }
 
+// handleUpdate 分发一条更新
+func handleUpdate(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	// 处理回调查询（按钮点击）
+	if update.CallbackQuery != nil {
+		callback_query.CallbackQueryHandler(bot, update)
+		return
+	}
+
+	if update.Message == nil {
+		command.StartCommand(bot, update)
+		return
+	}
+
+	if containsMagnetLink(update.Message.Text) {
+		middleware.MagnetMiddleWare(command.MagnetCommand)(bot, update)
+		return
+	}
+
+	// 处理上传的种子文件
+	if isTorrentDocument(update.Message.Document) {
+		middleware.MagnetMiddleWare(command.TorrentFileCommand)(bot, update)
+		return
+	}
+
+	// 处理命令
+	command.CommandHandler(bot, update)
+}
+
 // containsMagnetLink 检查文本是否包含磁力链接
 func containsMagnetLink(text string) bool {
 	return text != "" && strings.Contains(text, "magnet:")
 }
+
+// isTorrentDocument 检查消息附件是否为 .torrent 种子文件
+func isTorrentDocument(document *tgbotapi.Document) bool {
+	if document == nil {
+		return false
+	}
+	return strings.HasSuffix(strings.ToLower(document.FileName), ".torrent") ||
+		document.MimeType == "application/x-bittorrent"
+}
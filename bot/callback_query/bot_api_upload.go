@@ -0,0 +1,126 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/telegram"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Bot API 上传文件大小限制，小于该大小的文件直接通过 Bot API 发送
+const botAPIUploadLimit int64 = 50 * 1024 * 1024
+
+// 可以放入相册的文件类型
+const (
+	albumMediaPhoto = "photo"
+	albumMediaVideo = "video"
+)
+
+// albumMediaType 可以放入相册的文件类型：图片或 mp4 视频，其他文件返回空
+func albumMediaType(path string, size int64) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		if size <= telegram.PhotoSizeLimit {
+			return albumMediaPhoto
+		}
+	case ".mp4":
+		return albumMediaVideo
+	}
+	return ""
+}
+
+// sendFileByBotAPI 通过 Bot API 将文件直接发送给用户
+// 图片作为图片发送，mp4 作为视频发送，其余作为文件发送
+func sendFileByBotAPI(bot *tgbotapi.BotAPI, chatID int64, path string) (tgbotapi.Message, error) {
+	file := tgbotapi.FilePath(path)
+	caption := filepath.Base(path)
+
+	size := int64(0)
+	if stat, err := os.Stat(path); err == nil {
+		size = stat.Size()
+	}
+
+	switch albumMediaType(path, size) {
+	case albumMediaPhoto:
+		photo := tgbotapi.NewPhoto(chatID, file)
+		photo.Caption = caption
+		return common.SendWithRetry(bot, photo)
+	case albumMediaVideo:
+		video := tgbotapi.NewVideo(chatID, file)
+		video.Caption = caption
+		video.SupportsStreaming = true
+		return common.SendWithRetry(bot, video)
+	}
+
+	document := tgbotapi.NewDocument(chatID, file)
+	return common.SendWithRetry(bot, document)
+}
+
+// sendAlbumByBotAPI 通过 Bot API 将图片/视频作为相册发送给用户
+func sendAlbumByBotAPI(bot *tgbotapi.BotAPI, chatID int64, paths []string) ([]tgbotapi.Message, error) {
+	media := make([]interface{}, 0, len(paths))
+	for _, path := range paths {
+		file := tgbotapi.FilePath(path)
+		if strings.EqualFold(filepath.Ext(path), ".mp4") {
+			video := tgbotapi.NewInputMediaVideo(file)
+			video.Caption = filepath.Base(path)
+			video.SupportsStreaming = true
+			media = append(media, video)
+		} else {
+			photo := tgbotapi.NewInputMediaPhoto(file)
+			photo.Caption = filepath.Base(path)
+			media = append(media, photo)
+		}
+	}
+	return common.SendMediaGroupWithRetry(bot, tgbotapi.NewMediaGroup(chatID, media))
+}
+
+// mirrorToDiscussion 将发送给用户的消息复制到缓存频道消息的评论区，返回评论消息 ID
+func mirrorToDiscussion(bot *tgbotapi.BotAPI, fromChatID int64, messageID int, discussionChatID int64, threadMessageID int) (int, error) {
+	copyMessage := tgbotapi.NewCopyMessage(discussionChatID, fromChatID, messageID)
+	copyMessage.ReplyToMessageID = threadMessageID
+	message, err := common.SendWithRetry(bot, copyMessage)
+	if err != nil {
+		return 0, err
+	}
+	return message.MessageID, nil
+}
+
+// mirrorAlbumToDiscussion 使用已上传文件的 file_id 将相册重新发送到评论区，无需再次上传
+// copyMessages 不支持回复评论串，所以不能直接复制
+func mirrorAlbumToDiscussion(bot *tgbotapi.BotAPI, messages []tgbotapi.Message, discussionChatID int64, threadMessageID int) ([]int, error) {
+	media := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		switch {
+		case len(message.Photo) > 0:
+			// 最后一个是最大尺寸
+			photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(message.Photo[len(message.Photo)-1].FileID))
+			photo.Caption = message.Caption
+			media = append(media, photo)
+		case message.Video != nil:
+			video := tgbotapi.NewInputMediaVideo(tgbotapi.FileID(message.Video.FileID))
+			video.Caption = message.Caption
+			video.SupportsStreaming = true
+			media = append(media, video)
+		default:
+			return nil, errors.New("unexpected album message")
+		}
+	}
+
+	config := tgbotapi.NewMediaGroup(discussionChatID, media)
+	config.ReplyToMessageID = threadMessageID
+	mirrored, err := common.SendMediaGroupWithRetry(bot, config)
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]int, 0, len(mirrored))
+	for _, message := range mirrored {
+		messageIDs = append(messageIDs, message.MessageID)
+	}
+	return messageIDs, nil
+}
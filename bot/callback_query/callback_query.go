@@ -18,6 +18,30 @@ func CallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 		next := middleware.DownloadMiddleWare(FileCallbackQueryHandler)
 		next = middleware.DailyDownloadMiddleWare(next)
 		next(bot, update)
+	case strings.HasPrefix(data, "tree_"):
+		FileTreeCallbackQueryHandler(bot, update)
+	case strings.HasPrefix(data, "treedl_"):
+		next := middleware.DownloadMiddleWare(FolderDownloadCallbackQueryHandler)
+		next = middleware.DailyDownloadMiddleWare(next)
+		next(bot, update)
+	case strings.HasPrefix(data, "pickdl_"):
+		next := middleware.DownloadMiddleWare(FilePickerDownloadCallbackQueryHandler)
+		next = middleware.DailyDownloadMiddleWare(next)
+		next(bot, update)
+	case strings.HasPrefix(data, "pick"):
+		FilePickerCallbackQueryHandler(bot, update)
+	case strings.HasPrefix(data, "histdl_"):
+		next := middleware.DownloadMiddleWare(HistoryDownloadCallbackQueryHandler)
+		next = middleware.DailyDownloadMiddleWare(next)
+		next(bot, update)
+	case strings.HasPrefix(data, "hist_"):
+		HistoryCallbackQueryHandler(bot, update)
+	case strings.HasPrefix(data, "taskstop_"):
+		TaskStopCallbackQueryHandler(bot, update)
+	case strings.HasPrefix(data, "taskjump_"):
+		TaskJumpCallbackQueryHandler(bot, update)
+	case data == "taskrefresh":
+		TasksRefreshCallbackQueryHandler(bot, update)
 	case strings.HasPrefix(data, "stop_download_"):
 		StopCallbackQueryHandler(bot, update)
 	case strings.HasPrefix(data, "stop_magnet_"):
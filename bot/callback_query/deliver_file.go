@@ -0,0 +1,44 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// deliverCachedFile 文件已缓存时直接发送给用户，返回是否已发送
+func deliverCachedFile(bot *tgbotapi.BotAPI, chatID int64, infoHash string, fileIndex int, target *common.DownloadTarget, language string) bool {
+	if !deliverDownloadComment(bot, chatID, infoHash, fileIndex) {
+		return false
+	}
+
+	message := i18n.Text(i18n.DownloadSuccessMessageCode, language)
+	message = i18n.Replace(message, map[string]string{
+		i18n.DownloadMessagePlaceholderMagnet:          infoHash,
+		i18n.DownloadMessagePlaceholderDownloadFiles:   target.Name,
+		i18n.DownloadMessagePlaceholderDownloadChannel: common.DownloadChannel(),
+	})
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, message))
+	return true
+}
+
+// deliverDownloadComment 将缓存频道评论区中的文件消息复制到用户聊天，返回是否已发送
+func deliverDownloadComment(bot *tgbotapi.BotAPI, chatID int64, infoHash string, fileIndex int) bool {
+	commentChatID, messageIDs, ok, err := common.GetDownloadCommentMessages(infoHash, fileIndex)
+	if err != nil {
+		log.Println("get download comment messages error", err)
+		return false
+	}
+	if !ok || commentChatID == 0 || len(messageIDs) == 0 {
+		return false
+	}
+
+	// 批量复制，相册保持分组
+	if err := common.CopyMessages(bot, chatID, commentChatID, messageIDs); err != nil {
+		log.Println("copy download comment message error", err)
+		return false
+	}
+	return true
+}
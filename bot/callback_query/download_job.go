@@ -0,0 +1,248 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	middleware "bt-bot/bot/middle_ware"
+	"bt-bot/database/model"
+	"bt-bot/torrent"
+	"bt-bot/utils"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	t "github.com/anacrolix/torrent"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ResumeDownloadJobs 启动时恢复重启前未完成的下载任务
+// 同一用户的任务按创建顺序排队，与新的下载共用并发下载数量限制
+func ResumeDownloadJobs(bot *tgbotapi.BotAPI) {
+	jobs, err := common.UnfinishedDownloadJobs()
+	if err != nil {
+		log.Println("load unfinished download jobs error", err)
+		return
+	}
+
+	log.Printf("resume %d download jobs", len(jobs))
+	userJobs := make(map[int64][]*model.DownloadJob)
+	for i := range jobs {
+		job := &jobs[i]
+		userJobs[job.UserID] = append(userJobs[job.UserID], job)
+	}
+	for userID, jobs := range userJobs {
+		go resumeUserDownloadJobs(bot, userID, jobs)
+	}
+}
+
+// resumeUserDownloadJobs 依次等待用户的下载槽位并执行任务
+func resumeUserDownloadJobs(bot *tgbotapi.BotAPI, userID int64, jobs []*model.DownloadJob) {
+	user, err := common.User(userID)
+	if err != nil {
+		log.Println("resume download job get user error", err)
+		return
+	}
+
+	for _, job := range jobs {
+		// 多次恢复仍未完成的任务可能导致了崩溃，不再执行
+		if job.Attempts >= common.MaxDownloadJobAttempts {
+			failResumedDownloadJob(bot, job, user.Language)
+			continue
+		}
+		if err := common.IncrementDownloadJobAttempts(job); err != nil {
+			log.Println("increment download job attempts error", err)
+		}
+
+		permissions, err := common.Permissions(userID)
+		if err != nil {
+			log.Println("resume download job get permissions error", err)
+			return
+		}
+		release := middleware.WaitDownloadSlot(user.UUID, permissions.AsyncDownloadQuantity)
+		go func() {
+			defer release()
+			RunDownloadJob(bot, job)
+		}()
+	}
+}
+
+func failResumedDownloadJob(bot *tgbotapi.BotAPI, job *model.DownloadJob, language string) {
+	errMessage := "Too many restarts"
+	common.SetDownloadJobState(job, model.DownloadJobStateFailed, errMessage)
+	fileName := job.InfoHash
+	if torrentInfo, err := common.GetTorrentInfo(job.InfoHash); err == nil {
+		if target, err := common.ResolveDownloadTarget(torrentInfo, job.FileIndex); err == nil {
+			fileName = target.Name
+		}
+	}
+	message := i18n.Text(i18n.DownloadFailedMessageCode, language)
+	message = i18n.Replace(message, map[string]string{
+		i18n.DownloadMessagePlaceholderMagnet:        job.InfoHash,
+		i18n.DownloadMessagePlaceholderErrorMessage:  errMessage,
+		i18n.DownloadMessagePlaceholderDownloadFiles: fileName,
+	})
+	common.SendWithRetry(bot, tgbotapi.NewEditMessageText(job.ChatID, job.MessageID, message))
+}
+
+// RunDownloadJob 执行下载任务，进度等消息通过任务记录的 ChatID 和 MessageID 更新
+func RunDownloadJob(bot *tgbotapi.BotAPI, job *model.DownloadJob) {
+	chatID := job.ChatID
+	messageID := job.MessageID
+	infoHash := job.InfoHash
+	fileIndex := job.FileIndex
+
+	user, err := common.User(job.UserID)
+	if err != nil {
+		log.Println("get user error", err)
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, err.Error())
+		return
+	}
+
+	torrentInfo, err := common.GetTorrentInfo(infoHash)
+	if err != nil {
+		log.Println("get torrent info error", err)
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, err.Error())
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	target, err := common.ResolveDownloadTarget(torrentInfo, fileIndex)
+	if err != nil {
+		log.Println("resolve download target error", err)
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, err.Error())
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	if err := common.SetDownloadJobState(job, model.DownloadJobStateDownloading, ""); err != nil {
+		log.Println("set download job state error", err)
+	}
+
+	// 下载进度，耗时从任务创建开始计算
+	startTime := time.Unix(job.CreatedAt, 0)
+	progressCallback := func(params torrent.ProgressParams) {
+		message := i18n.Text(i18n.DownloadProcessingMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:         infoHash,
+			i18n.DownloadMessagePlaceholderDownloadFiles:  params.FileName,
+			i18n.DownloadMessagePlaceholderPercent:        utils.FormatPercentage(params.BytesCompleted, params.TotalBytes),
+			i18n.DownloadMessagePlaceholderBytesCompleted: utils.FormatBytesToSizeString(params.BytesCompleted),
+			i18n.DownloadMessagePlaceholderTotalBytes:     utils.FormatBytesToSizeString(params.TotalBytes),
+			i18n.DownloadMessagePlaceholderElapsedTime:    utils.FormatDuration(time.Since(startTime)),
+			i18n.DownloadMessagePlaceholderSpeed:          utils.FormatSpeed(params.Speed),
+			i18n.DownloadMessagePlaceholderPeers:          strconv.Itoa(params.ActivePeers),
+			i18n.DownloadMessagePlaceholderSeeders:        strconv.Itoa(params.ConnectedSeeders),
+			i18n.DownloadMessagePlaceholderETA:            utils.FormatETA(params.TotalBytes-params.BytesCompleted, params.Speed),
+		})
+		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
+		newEditMessage.ReplyMarkup = stopDownloadReplyMarkup(infoHash, fileIndex, user.Language)
+		common.SendWithRetry(bot, newEditMessage)
+	}
+
+	// 下载取消
+	cancelCallback := func(t *t.Torrent) {
+		common.SetDownloadJobState(job, model.DownloadJobStateCanceled, "")
+		message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
+			i18n.DownloadMessagePlaceholderErrorMessage:  "Cancel",
+			i18n.DownloadMessagePlaceholderDownloadFiles: target.Name,
+		})
+		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
+		common.SendWithRetry(bot, newEditMessage)
+	}
+
+	// 下载超时
+	timeoutCallback := func(t *t.Torrent) {
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, "Timeout")
+		message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
+			i18n.DownloadMessagePlaceholderErrorMessage:  "Timeout",
+			i18n.DownloadMessagePlaceholderDownloadFiles: target.Name,
+		})
+		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
+		common.SendWithRetry(bot, newEditMessage)
+	}
+
+	// 下载停滞
+	stalledCallback := func(t *t.Torrent) {
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, "Stalled")
+		message := i18n.Text(i18n.DownloadStalledMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
+			i18n.DownloadMessagePlaceholderElapsedTime:   utils.FormatDuration(time.Since(startTime)),
+			i18n.DownloadMessagePlaceholderDownloadFiles: target.Name,
+		})
+		common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
+	}
+
+	// 下载成功
+	successCallback := func(t *t.Torrent) {
+		common.SetDownloadJobState(job, model.DownloadJobStateUploading, "")
+
+		// 发送文件发送消息，上传过程中持续更新上传进度
+		reporter := newUploadProgressReporter(bot, chatID, messageID, infoHash, target.Name, user.Language)
+		reporter.Start()
+
+		// 发送文件给用户，同时上传到缓存频道
+		// 文件已由其他任务上传时，从缓存频道复制给用户
+		sent, err := sendDownloadMessage(bot, chatID, infoHash, fileIndex, target, t, user.Premium, reporter)
+		reporter.Stop()
+		if err == nil && !sent && !deliverDownloadComment(bot, chatID, infoHash, fileIndex) {
+			err = errors.New("deliver cached file failed")
+		}
+		if err != nil {
+			common.SetDownloadJobState(job, model.DownloadJobStateFailed, err.Error())
+			message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
+			message = i18n.Replace(message, map[string]string{
+				i18n.DownloadMessagePlaceholderMagnet:        infoHash,
+				i18n.DownloadMessagePlaceholderErrorMessage:  err.Error(),
+				i18n.DownloadMessagePlaceholderDownloadFiles: target.Name,
+			})
+			common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
+			return
+		}
+
+		// 发送下载成功消息
+		message := i18n.Text(i18n.DownloadSuccessMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:          infoHash,
+			i18n.DownloadMessagePlaceholderDownloadFiles:   target.Name,
+			i18n.DownloadMessagePlaceholderDownloadChannel: common.DownloadChannel(),
+		})
+		common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
+
+		common.SetDownloadJobState(job, model.DownloadJobStateDone, "")
+	}
+
+	params := torrent.DownloadParams{
+		InfoHash:         infoHash,
+		FileIndex:        fileIndex,
+		FileIndexes:      target.FileIndexes,
+		FileName:         target.Name,
+		UserID:           job.UserID,
+		MetaInfo:         torrentInfo.MetaInfo,
+		ChatID:           chatID,
+		MessageID:        messageID,
+		StartedAt:        startTime,
+		ProgressCallback: progressCallback,
+		CancelCallback:   cancelCallback,
+		TimeoutCallback:  timeoutCallback,
+		StalledCallback:  stalledCallback,
+		SuccessCallback:  successCallback,
+	}
+
+	// 元信息获取失败
+	if err := torrent.Download(params); err != nil {
+		common.SetDownloadJobState(job, model.DownloadJobStateFailed, err.Error())
+		message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
+		message = i18n.Replace(message, map[string]string{
+			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
+			i18n.DownloadMessagePlaceholderErrorMessage:  err.Error(),
+			i18n.DownloadMessagePlaceholderDownloadFiles: target.Name,
+		})
+		common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
+	}
+}
@@ -3,20 +3,19 @@ package callback_query
 import (
 	"bt-bot/bot/common"
 	"bt-bot/bot/i18n"
-	"bt-bot/database/model"
 	"bt-bot/telegram"
 	"bt-bot/torrent"
 	"bt-bot/utils"
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	t "github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -56,13 +55,27 @@ func FileCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 		common.SendErrorMessage(bot, chatID, user.Language, err)
 		return
 	}
-	if checkOverDownloadSize(infoHash, fileIndex, torrentInfo, permissions) {
+	target, err := common.ResolveDownloadTarget(torrentInfo, fileIndex)
+	if err != nil {
+		log.Println("resolve download target error", err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, "❌ invalid download file data"))
+		return
+	}
+	if target.Length > permissions.FileDownloadSize {
 		messageText := i18n.Text(i18n.DownloadFileDownloadSizeNotEnoughMessageCode, user.Language)
 		reply := tgbotapi.NewMessage(chatID, messageText)
 		common.SendWithRetry(bot, reply)
 		return
 	}
 
+	// 文件已缓存，直接从缓存频道转发给用户，无需下载
+	if deliverCachedFile(bot, chatID, infoHash, fileIndex, target, user.Language) {
+		if _, err := common.CreateDownloadHistory(user.UUID, infoHash, fileIndex, target, 0); err != nil {
+			log.Println("create download history error", err)
+		}
+		return
+	}
+
 	// 发送开始下载消息
 	startMessage := i18n.Text(i18n.DownloadStartMessageCode, user.Language)
 	startMessage = i18n.Replace(startMessage, map[string]string{
@@ -77,85 +90,19 @@ func FileCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 	}
 	messageID := message.MessageID
 
-	// 下载进度
-	startTime := time.Now()
-	progressCallback := func(params torrent.ProgressParams) {
-		elapsedTime := time.Since(startTime)
-		hours := int(elapsedTime.Hours())
-		minutes := int(elapsedTime.Minutes()) % 60
-		seconds := int(elapsedTime.Seconds()) % 60
-		elapsedTimeString := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
-		message := i18n.Text(i18n.DownloadProcessingMessageCode, user.Language)
-		message = i18n.Replace(message, map[string]string{
-			i18n.DownloadMessagePlaceholderMagnet:         infoHash,
-			i18n.DownloadMessagePlaceholderDownloadFiles:  params.FileName,
-			i18n.DownloadMessagePlaceholderPercent:        utils.FormatPercentage(params.BytesCompleted, params.TotalBytes),
-			i18n.DownloadMessagePlaceholderBytesCompleted: utils.FormatBytesToSizeString(params.BytesCompleted),
-			i18n.DownloadMessagePlaceholderTotalBytes:     utils.FormatBytesToSizeString(params.TotalBytes),
-			i18n.DownloadMessagePlaceholderElapsedTime:    elapsedTimeString,
-		})
-		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
-		newEditMessage.ReplyMarkup = stopDownloadReplyMarkup(infoHash, fileIndex, user.Language)
-		common.SendWithRetry(bot, newEditMessage)
-	}
-
-	// 下载取消
-	cancelCallback := func(t *t.Torrent) {
-		message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
-		message = i18n.Replace(message, map[string]string{
-			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
-			i18n.DownloadMessagePlaceholderErrorMessage:  "Cancel",
-			i18n.DownloadMessagePlaceholderDownloadFiles: parseFileName(t, fileIndex),
-		})
-		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
-		common.SendWithRetry(bot, newEditMessage)
-	}
-
-	// 下载超时
-	timeoutCallback := func(t *t.Torrent) {
-		message := i18n.Text(i18n.DownloadFailedMessageCode, user.Language)
-		message = i18n.Replace(message, map[string]string{
-			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
-			i18n.DownloadMessagePlaceholderErrorMessage:  "Timeout",
-			i18n.DownloadMessagePlaceholderDownloadFiles: parseFileName(t, fileIndex),
-		})
-		newEditMessage := tgbotapi.NewEditMessageText(chatID, messageID, message)
-		common.SendWithRetry(bot, newEditMessage)
-	}
-
-	// 下载成功
-	successCallback := func(t *t.Torrent) {
-		// 发送文件发送消息
-		message := i18n.Text(i18n.DownloadSendFileMessageCode, user.Language)
-		message = i18n.Replace(message, map[string]string{
-			i18n.DownloadMessagePlaceholderMagnet:        infoHash,
-			i18n.DownloadMessagePlaceholderDownloadFiles: parseFileName(t, fileIndex),
-		})
-		common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
-
-		// 发送下载消息
-		sendDownloadMessage(infoHash, fileIndex, t, user.Premium)
-
-		// 发送下载成功消息
-		message = i18n.Text(i18n.DownloadSuccessMessageCode, user.Language)
-		message = i18n.Replace(message, map[string]string{
-			i18n.DownloadMessagePlaceholderMagnet:          infoHash,
-			i18n.DownloadMessagePlaceholderDownloadFiles:   parseFileName(t, fileIndex),
-			i18n.DownloadMessagePlaceholderDownloadChannel: "@tgqpXOZ2tzXN",
-		})
-		common.SendWithRetry(bot, tgbotapi.NewEditMessageText(chatID, messageID, message))
+	// 记录下载任务，重启后可恢复
+	job, err := common.CreateDownloadJob(userId, chatID, messageID, infoHash, fileIndex)
+	if err != nil {
+		log.Println("create download job error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
 	}
-
-	params := torrent.DownloadParams{
-		InfoHash:         infoHash,
-		FileIndex:        fileIndex,
-		ProgressCallback: progressCallback,
-		CancelCallback:   cancelCallback,
-		TimeoutCallback:  timeoutCallback,
-		SuccessCallback:  successCallback,
+	// 下载记录在任务结束时更新结果
+	if _, err := common.CreateDownloadHistory(user.UUID, infoHash, fileIndex, target, job.ID); err != nil {
+		log.Println("create download history error", err)
 	}
 
-	torrent.Download(params)
+	RunDownloadJob(bot, job)
 }
 
 func parseFileCallbackQueryData(data string) (string, int, error) {
@@ -181,22 +128,9 @@ func stopDownloadReplyMarkup(infoHash string, fileIndex int, language string) *t
 	}
 }
 
-func parseFileName(t *t.Torrent, fileIndex int) string {
-	if fileIndex == -1 {
-		return "All files"
-	} else if fileIndex == -2 {
-		return "All images"
-	} else if fileIndex == -3 {
-		return "All videos"
-	}
-	files := t.Files()
-	if fileIndex < 0 || fileIndex >= len(files) {
-		return "Invalid file index"
-	}
-	return files[fileIndex].DisplayPath()
-}
-
-func sendDownloadMessage(infoHash string, fileIndex int, t *t.Torrent, premium string) {
+// sendDownloadMessage 上传下载的文件到缓存频道并发送给用户，返回文件是否已发送给用户
+// 文件已缓存时返回 false，由调用方从缓存频道复制给用户；上传失败时返回错误
+func sendDownloadMessage(bot *tgbotapi.BotAPI, chatID int64, infoHash string, fileIndex int, target *common.DownloadTarget, t *t.Torrent, premium string, reporter *uploadProgressReporter) (bool, error) {
 	messageId, ok, _ := common.CheckDownloadMessage(infoHash)
 	if !ok {
 		messageText := `
@@ -209,7 +143,7 @@ func sendDownloadMessage(infoHash string, fileIndex int, t *t.Torrent, premium s
 		messageId_, err := telegram.SendChannelMessage(messageText)
 		if err != nil {
 			log.Println("send download message error", err)
-			return
+			return false, err
 		}
 		messageId = int64(messageId_)
 
@@ -217,7 +151,7 @@ func sendDownloadMessage(infoHash string, fileIndex int, t *t.Torrent, premium s
 		files := t.Info().Files
 		filesText := ""
 		for index, file := range files {
-			filesText += fmt.Sprintf("%s %d. %s (%s)\n", emojifyFilename(file.DisplayPath(t.Info())), index+1, file.DisplayPath(t.Info()), utils.FormatBytesToSizeString(file.Length))
+			filesText += fmt.Sprintf("%s %d. %s (%s)\n", common.EmojifyFilename(file.DisplayPath(t.Info())), index+1, file.DisplayPath(t.Info()), utils.FormatBytesToSizeString(file.Length))
 			if (index+1)%48 == 0 {
 				telegram.SendCommentMessageText(filesText, int(messageId))
 				filesText = ""
@@ -234,160 +168,101 @@ func sendDownloadMessage(infoHash string, fileIndex int, t *t.Torrent, premium s
 	}
 
 	// 发送下载文件评论
-	sendDownloadComment(infoHash, fileIndex, t, messageId, premium)
+	return sendDownloadComment(bot, chatID, infoHash, fileIndex, target, t, messageId, premium, reporter)
 }
 
-func sendDownloadComment(infoHash string, fileIndex int, t *t.Torrent, messageId int64, premium string) {
+// sendDownloadComment 将文件发送到缓存频道消息的评论区并发送给用户，返回文件是否已发送给用户
+// 小于 Bot API 上传限制的文件直接发送给用户再复制到评论区，大文件通过帐号上传到评论区再复制给用户
+// 帐号上传失败时返回错误，下载的文件同样会被释放
+func sendDownloadComment(bot *tgbotapi.BotAPI, chatID int64, infoHash string, fileIndex int, target *common.DownloadTarget, t *t.Torrent, messageId int64, premium string, reporter *uploadProgressReporter) (bool, error) {
 	ok, err := common.CheckDownloadComment(infoHash, fileIndex)
 	if ok {
-		return
+		return false, nil
 	}
 	if err != nil {
 		log.Println("check download comment error", err)
 	}
 
-	filePaths := []string{}
-	fileName := t.Info().Name
-	if t.Info().NameUtf8 != "" {
-		fileName = t.Info().NameUtf8
+	filePaths := make([]string, 0, len(target.FileIndexes))
+	for _, index := range target.FileIndexes {
+		filePaths = append(filePaths, downloadFilePath(t.Info(), index))
 	}
-	downloadDir := filepath.Join(torrent.DownloadDir, fileName)
-	if fileIndex == -1 {
-		if t.Info().IsDir() {
-			filePaths = append(filePaths, downloadDir)
-		} else {
-			for _, file := range t.Info().Files {
-				filePaths = append(filePaths, filepath.Join(downloadDir, file.DisplayPath(t.Info())))
-			}
-		}
-	} else if fileIndex == -2 {
-		for _, file := range t.Info().Files {
-			if torrent.HasImageExtension(file.DisplayPath(t.Info())) {
-				filePaths = append(filePaths, filepath.Join(downloadDir, file.DisplayPath(t.Info())))
-			}
-		}
-	} else if fileIndex == -3 {
-		for _, file := range t.Info().Files {
-			if torrent.HasVideoExtension(file.DisplayPath(t.Info())) {
-				filePaths = append(filePaths, filepath.Join(downloadDir, file.DisplayPath(t.Info())))
+
+	// Bot 不在讨论组中时无法复制到评论区，全部通过帐号上传
+	discussionChatID, err := common.DiscussionChatID(bot)
+	if err != nil {
+		log.Println("get discussion chat id error", err)
+	}
+
+	accountLimit, err := telegram.UploadLimit()
+	if err != nil {
+		log.Println("get upload limit error", err)
+	}
+
+	// 连续的图片/视频合并为相册发送
+	batches := planUploadBatches(filePaths, discussionChatID != 0, accountLimit)
+
+	commentChatID := int64(0)
+	commentMessageIDs := []int{}
+	mirrored := true
+	fileNumber := 1
+	for _, batch := range batches {
+		reporter.StartFile(fileNumber, len(filePaths), batch.paths[0])
+		fileNumber += len(batch.paths)
+
+		if batch.botAPI {
+			messageIDs, sent, err := sendBatchByBotAPI(bot, chatID, batch, discussionChatID, int(messageId))
+			if sent {
+				if err != nil {
+					// 复制失败时缓存不完整，不记录评论，下次重新下载
+					log.Println("mirror download file to discussion error", err)
+					mirrored = false
+					continue
+				}
+				commentChatID = discussionChatID
+				commentMessageIDs = append(commentMessageIDs, messageIDs...)
+				continue
 			}
+			log.Println("send file by bot api failed, fallback to account upload")
 		}
-	} else {
-		file := t.Info().Files[fileIndex]
-		filePaths = append(filePaths, filepath.Join(downloadDir, file.DisplayPath(t.Info())))
-	}
 
-	for _, filePath := range filePaths {
-		err := telegram.SendCommentMessage(filePath, int(messageId))
+		comments, err := sendBatchByAccount(bot, chatID, batch, int(messageId), reporter.Progress)
 		if err != nil {
 			log.Println("send download comment error", err)
-			return
+			torrent.DeleteFilesAfterRelease(infoHash, filePaths)
+			return false, err
+		}
+		// 大文件的多个分卷和相册中的文件全部记录，以便一起转发
+		for _, comment := range comments {
+			if comment.MessageID != 0 {
+				commentChatID = comment.ChatID
+				commentMessageIDs = append(commentMessageIDs, comment.MessageID)
+			}
 		}
 		time.Sleep(2 * time.Second)
 	}
 
-	if err := common.RecordDownloadComment(infoHash, fileIndex); err != nil {
-		log.Println("record download comment error", err)
-		return
+	if mirrored {
+		// 文件已发送给用户，记录失败只影响缓存
+		if err := common.RecordDownloadComment(infoHash, fileIndex, commentChatID, commentMessageIDs); err != nil {
+			log.Println("record download comment error", err)
+		}
 	}
 
-	deleteDownloadFile(filePaths)
+	torrent.DeleteFilesAfterRelease(infoHash, filePaths)
 
 	err = common.DecrementDailyDownloadQuantity(premium)
 	if err != nil {
 		log.Println("decrement daily download quantity error", err)
 	}
+	return true, nil
 }
 
-func deleteDownloadFile(filePath []string) {
-	for _, filePath := range filePath {
-		err := os.Remove(filePath)
-		if err != nil {
-			log.Println("delete download file error", err)
-			return
-		}
-	}
-}
-
-func emojifyFilename(filename string) string {
-	// 根据文件后缀返回带有 emoji 的文件名
-	extToEmoji := map[string]string{
-		".mp4":     "🎬",
-		".mkv":     "🎥",
-		".avi":     "📽️",
-		".mov":     "🎞️",
-		".ts":      "📼",
-		".mp3":     "🎵",
-		".flac":    "🎶",
-		".wav":     "🔊",
-		".ape":     "🎼",
-		".aac":     "🎧",
-		".ogg":     "🎶",
-		".jpg":     "🖼️",
-		".jpeg":    "🖼️",
-		".png":     "📸",
-		".gif":     "🎞️",
-		".webp":    "🌆",
-		".bmp":     "🖼️",
-		".zip":     "🗜️",
-		".rar":     "🗂️",
-		".7z":      "📦",
-		".tar":     "📦",
-		".gz":      "🗄️",
-		".pdf":     "📑",
-		".epub":    "📚",
-		".txt":     "📄",
-		".doc":     "📝",
-		".docx":    "📝",
-		".ppt":     "📊",
-		".pptx":    "📊",
-		".xls":     "📈",
-		".xlsx":    "📈",
-		".apk":     "🤖",
-		".exe":     "🖥️",
-		".iso":     "💿",
-		".torrent": "🧲",
-	}
-
-	ext := ""
-	for i := len(filename) - 1; i >= 0; i-- {
-		if filename[i] == '.' {
-			ext = filename[i:]
-			break
-		}
-	}
-	emoji := ""
-	if val, ok := extToEmoji[ext]; ok {
-		emoji = val
-	}
-	if emoji != "" {
-		return emoji
-	} else {
-		return "📄"
-	}
-}
-
-func checkOverDownloadSize(infoHash string, fileIndex int, torrentInfo *model.Torrent, permissions *model.Permissions) bool {
-	switch fileIndex {
-	case -1:
-		return torrentInfo.TotalLength() > permissions.FileDownloadSize
-	case -2:
-		totalLength := int64(0)
-		for _, file := range torrentInfo.Files {
-			if torrent.HasImageExtension(file.DisplayPath()) {
-				totalLength += file.Length
-			}
-		}
-		return totalLength > permissions.FileDownloadSize
-	case -3:
-		totalLength := int64(0)
-		for _, file := range torrentInfo.Files {
-			if torrent.HasVideoExtension(file.DisplayPath()) {
-				totalLength += file.Length
-			}
-		}
-		return totalLength > permissions.FileDownloadSize
-	default:
-		return torrentInfo.Files[fileIndex].Length > permissions.FileDownloadSize
+// downloadFilePath 下载文件在磁盘上的路径，与存储使用的名称一致（优先使用 UTF-8 名称）
+func downloadFilePath(info *metainfo.Info, fileIndex int) string {
+	if !info.IsDir() {
+		return filepath.Join(torrent.DownloadDir, info.BestName())
 	}
+	file := info.Files[fileIndex]
+	return filepath.Join(torrent.DownloadDir, info.BestName(), file.DisplayPath(info))
 }
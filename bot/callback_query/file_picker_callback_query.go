@@ -0,0 +1,166 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/database/model"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// FilePickerCallbackQueryHandler 多选模式的按钮：进入、退出多选，切换文件或文件夹的选中状态
+// pick_<infoHash>_<文件序号>_<文件夹 ID>_<页码>
+// pickdir_<infoHash>_<文件夹 ID>_<页码>
+// pickon_<infoHash>_<文件夹 ID>_<页码>
+// pickoff_<infoHash>_<文件夹 ID>_<页码>
+func FilePickerCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	messageID := update.CallbackQuery.Message.MessageID
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	action, infoHash, values, err := parseFilePickerCallbackQueryData(update.CallbackQuery.Data)
+	if err != nil {
+		log.Println("parse file picker callback query data error", err)
+		return
+	}
+	// 最后两个值总是当前的文件夹 ID 和页码
+	dirID, page := values[len(values)-2], values[len(values)-1]
+
+	switch action {
+	case "pickon":
+		common.StartFilePicker(chatID, messageID, infoHash)
+	case "pickoff":
+		common.StopFilePicker(chatID, messageID)
+	case "pick", "pickdir":
+		torrentInfo, err := common.GetTorrentInfo(infoHash)
+		if err != nil {
+			log.Println("get torrent info error", err)
+			common.SendErrorMessage(bot, chatID, user.Language, err)
+			return
+		}
+		indexes, err := pickFileIndexes(torrentInfo, action, values)
+		if err != nil {
+			log.Println("pick file indexes error", err)
+			return
+		}
+		common.ToggleFilePicks(chatID, messageID, infoHash, indexes)
+	}
+
+	editFileTreeMessage(bot, update, user.Language, infoHash, dirID, page)
+}
+
+// FilePickerDownloadCallbackQueryHandler 将已选文件作为一个下载任务
+// pickdl_<infoHash>_<文件夹 ID>_<页码>
+func FilePickerDownloadCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	messageID := update.CallbackQuery.Message.MessageID
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	permissions, err := common.Permissions(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	_, infoHash, values, err := parseFilePickerCallbackQueryData(update.CallbackQuery.Data)
+	if err != nil {
+		log.Println("parse file picker callback query data error", err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, "❌ invalid download file data"))
+		return
+	}
+
+	picks := common.FilePickerPicks(chatID, messageID, infoHash)
+	if len(picks) == 0 {
+		messageText := i18n.Text(i18n.MagnetPickerEmptyMessageCode, user.Language)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, messageText))
+		return
+	}
+
+	torrentInfo, err := common.GetTorrentInfo(infoHash)
+	if err != nil {
+		log.Println("get torrent info error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	// 超过大小限制时保留已选文件，方便取消部分文件后重试
+	length := int64(0)
+	for _, file := range common.TorrentFiles(torrentInfo) {
+		if picks[file.FileIndex] {
+			length += file.Length
+		}
+	}
+	if length > permissions.FileDownloadSize {
+		messageText := i18n.Text(i18n.DownloadFileDownloadSizeNotEnoughMessageCode, user.Language)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, messageText))
+		return
+	}
+
+	selectionName := i18n.Replace(i18n.Text(i18n.MagnetPickerSelectionNameCode, user.Language), map[string]string{
+		i18n.MagnetMessagePlaceholderFileCount: strconv.Itoa(len(picks)),
+	})
+	selection, err := common.SaveFileSelection(infoHash, selectionName, picks.Indexes())
+	if err != nil {
+		log.Println("save file selection error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	// 退出多选模式，下载进度使用新消息显示
+	common.StopFilePicker(chatID, messageID)
+	editFileTreeMessage(bot, update, user.Language, infoHash, values[0], values[1])
+
+	update.CallbackQuery.Data = fmt.Sprintf("file_%s_%d", infoHash, model.SelectionFileIndex(selection.ID))
+	FileCallbackQueryHandler(bot, update)
+}
+
+// pickFileIndexes 按钮对应的文件序号，pick 为单个文件，pickdir 为文件夹中的所有文件
+func pickFileIndexes(torrentInfo *model.Torrent, action string, values []int) ([]int, error) {
+	if action == "pickdir" {
+		dir, ok := common.BuildFileTree(torrentInfo).Dir(values[0])
+		if !ok {
+			return nil, errors.New("invalid folder")
+		}
+		return dir.FileIndexes(), nil
+	}
+
+	for _, file := range common.TorrentFiles(torrentInfo) {
+		if file.FileIndex == values[0] {
+			return []int{file.FileIndex}, nil
+		}
+	}
+	return nil, errors.New("invalid file index")
+}
+
+func parseFilePickerCallbackQueryData(data string) (string, string, []int, error) {
+	split := strings.Split(data, "_")
+	count := map[string]int{"pick": 5, "pickdir": 4, "pickon": 4, "pickoff": 4, "pickdl": 4}
+	if len(split) != count[split[0]] {
+		return "", "", nil, errors.New("invalid data")
+	}
+
+	values := make([]int, 0, len(split)-2)
+	for _, value := range split[2:] {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return "", "", nil, err
+		}
+		values = append(values, number)
+	}
+	return split[0], split[1], values, nil
+}
@@ -0,0 +1,141 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/database/model"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// FileTreeCallbackQueryHandler 进入文件夹或翻页，编辑原消息显示新的文件夹内容
+func FileTreeCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	infoHash, dirID, page, err := parseFileTreeCallbackQueryData(update.CallbackQuery.Data)
+	if err != nil {
+		log.Println("parse file tree callback query data error", err)
+		return
+	}
+
+	editFileTreeMessage(bot, update, user.Language, infoHash, dirID, page)
+}
+
+// editFileTreeMessage 按当前的多选状态重新生成文件浏览消息
+func editFileTreeMessage(bot *tgbotapi.BotAPI, update *tgbotapi.Update, language string, infoHash string, dirID int, page int) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	messageID := update.CallbackQuery.Message.MessageID
+
+	torrentInfo, err := common.GetTorrentInfo(infoHash)
+	if err != nil {
+		log.Println("get torrent info error", err)
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+
+	view := common.FileTreeView{
+		DirID: dirID,
+		Page:  page,
+		Picks: common.FilePickerPicks(chatID, messageID, infoHash),
+	}
+	if view.Picks != nil {
+		permissions, err := common.Permissions(userId)
+		if err != nil {
+			common.SendErrorMessage(bot, chatID, language, err)
+			return
+		}
+		view.SizeLimit = permissions.FileDownloadSize
+	}
+
+	text, replyMarkup, err := common.FileTreeMessage(torrentInfo, view, language)
+	if err != nil {
+		log.Println("create file tree message error", err)
+		return
+	}
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	editMsg.ReplyMarkup = replyMarkup
+	if _, err := common.SendWithRetry(bot, editMsg); err != nil {
+		log.Println("edit file tree message error", err)
+	}
+}
+
+// FolderDownloadCallbackQueryHandler 下载整个文件夹
+// 文件夹中的文件保存为一个选择，之后按普通文件下载处理
+func FolderDownloadCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	infoHash, dirID, err := parseFolderDownloadCallbackQueryData(update.CallbackQuery.Data)
+	if err != nil {
+		log.Println("parse folder download callback query data error", err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, "❌ invalid download file data"))
+		return
+	}
+
+	torrentInfo, err := common.GetTorrentInfo(infoHash)
+	if err != nil {
+		log.Println("get torrent info error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	dir, ok := common.BuildFileTree(torrentInfo).Dir(dirID)
+	if !ok {
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, "❌ invalid download file data"))
+		return
+	}
+
+	selection, err := common.SaveFileSelection(infoHash, dir.Path()+"/", dir.FileIndexes())
+	if err != nil {
+		log.Println("save file selection error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	update.CallbackQuery.Data = fmt.Sprintf("file_%s_%d", infoHash, model.SelectionFileIndex(selection.ID))
+	FileCallbackQueryHandler(bot, update)
+}
+
+func parseFileTreeCallbackQueryData(data string) (string, int, int, error) {
+	split := strings.Split(data, "_")
+	if len(split) != 4 || split[0] != "tree" {
+		return "", 0, 0, errors.New("invalid data")
+	}
+	dirID, err := strconv.Atoi(split[2])
+	if err != nil {
+		return "", 0, 0, err
+	}
+	page, err := strconv.Atoi(split[3])
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return split[1], dirID, page, nil
+}
+
+func parseFolderDownloadCallbackQueryData(data string) (string, int, error) {
+	split := strings.Split(data, "_")
+	if len(split) != 3 || split[0] != "treedl" {
+		return "", 0, errors.New("invalid data")
+	}
+	dirID, err := strconv.Atoi(split[2])
+	if err != nil {
+		return "", 0, err
+	}
+	return split[1], dirID, nil
+}
@@ -0,0 +1,70 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HistoryCallbackQueryHandler 下载记录翻页：hist_<页码>
+func HistoryCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	page, err := strconv.Atoi(strings.TrimPrefix(update.CallbackQuery.Data, "hist_"))
+	if err != nil {
+		log.Println("parse history callback query data error", err)
+		return
+	}
+
+	text, replyMarkup, err := common.HistoryMessage(user, page, user.Language)
+	if err != nil {
+		log.Println("create history message error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+	editMsg := tgbotapi.NewEditMessageText(chatID, update.CallbackQuery.Message.MessageID, text)
+	editMsg.ReplyMarkup = replyMarkup
+	if _, err := common.SendWithRetry(bot, editMsg); err != nil {
+		log.Println("edit history message error", err)
+	}
+}
+
+// HistoryDownloadCallbackQueryHandler 重新获取下载记录中的文件：histdl_<记录 ID>
+// 按普通文件下载处理，已缓存时直接发送，否则重新下载
+func HistoryDownloadCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(update.CallbackQuery.Data, "histdl_"), 10, 64)
+	if err != nil {
+		log.Println("parse history download callback query data error", err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, "❌ invalid download file data"))
+		return
+	}
+
+	// 只能重新获取自己的记录
+	history, err := common.FindDownloadHistory(uint(id), user.UUID)
+	if err != nil {
+		log.Println("find download history error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	update.CallbackQuery.Data = fmt.Sprintf("file_%s_%d", history.InfoHash, history.FileIndex)
+	FileCallbackQueryHandler(bot, update)
+}
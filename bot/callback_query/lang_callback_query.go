@@ -29,8 +29,8 @@ func LangCallbackQueryHandler(bot *tgbotapi.BotAPI, udpate *tgbotapi.Update) {
 
 	text := i18n.Replace(i18n.Text("start_message", user.Language), map[string]string{
 		i18n.StartMessagePlaceholderUserName:        username,
-		i18n.StartMessagePlaceholderDownloadChannel: "@tgqpXOZ2tzXN",
-		i18n.StartMessagePlaceholderHelpChannel:     "@bt1bot1channel",
+		i18n.StartMessagePlaceholderDownloadChannel: common.DownloadChannel(),
+		i18n.StartMessagePlaceholderHelpChannel:     common.HelpChannel(),
 	})
 
 	message := tgbotapi.NewEditMessageText(udpate.CallbackQuery.Message.Chat.ID, udpate.CallbackQuery.Message.MessageID, text)
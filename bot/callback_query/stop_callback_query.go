@@ -29,7 +29,7 @@ func StopCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 		return
 	}
 
-	ok := torrent.DownloadCancel(infoHash, fileIndex)
+	ok := torrent.CancelDownloadTask(infoHash, fileIndex, userId)
 	if !ok {
 		editMsg := tgbotapi.NewEditMessageText(
 			update.CallbackQuery.Message.Chat.ID,
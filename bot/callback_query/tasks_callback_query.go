@@ -0,0 +1,146 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/torrent"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// taskCallbackData 任务按钮数据
+type taskCallbackData struct {
+	taskType  string
+	infoHash  string
+	fileIndex int
+	number    string
+}
+
+// TaskStopCallbackQueryHandler 取消任务列表中的任务，并刷新任务列表
+func TaskStopCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	data, err := parseTaskCallbackQueryData(update.CallbackQuery.Data, "taskstop")
+	if err != nil {
+		log.Println("parse task stop callback query data error", err)
+		return
+	}
+
+	// 只能取消自己的任务，取消结果由任务原来的进度消息显示
+	// 按钮可能来自较早的任务列表，任务已进入上传时忽略
+	if data.taskType == common.TaskTypeMagnet {
+		torrent.TorrentCancel(data.infoHash, userId)
+	} else {
+		torrent.CancelDownloadTask(data.infoHash, data.fileIndex, userId)
+	}
+
+	editTasksMessage(bot, update, user.Language)
+}
+
+// TaskJumpCallbackQueryHandler 回复任务的进度消息，点击回复即可跳转
+func TaskJumpCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	data, err := parseTaskCallbackQueryData(update.CallbackQuery.Data, "taskjump")
+	if err != nil {
+		log.Println("parse task jump callback query data error", err)
+		return
+	}
+
+	var taskChatID int64
+	var taskMessageID int
+	var found bool
+	if data.taskType == common.TaskTypeMagnet {
+		task, ok := torrent.FindMagnetTask(data.infoHash, userId)
+		taskChatID, taskMessageID, found = task.ChatID, task.MessageID, ok
+	} else {
+		task, ok := torrent.FindDownloadTask(data.infoHash, data.fileIndex, userId)
+		taskChatID, taskMessageID, found = task.ChatID, task.MessageID, ok
+	}
+
+	// 任务已结束，刷新任务列表
+	if !found || taskChatID != chatID {
+		editTasksMessage(bot, update, user.Language)
+		return
+	}
+
+	message := i18n.Replace(i18n.Text(i18n.TasksJumpMessageCode, user.Language), map[string]string{
+		i18n.TasksMessagePlaceholderNumber: data.number,
+	})
+	reply := tgbotapi.NewMessage(chatID, message)
+	reply.ReplyToMessageID = taskMessageID
+	if _, err := common.SendWithRetry(bot, reply); err != nil {
+		log.Println("send task jump message error", err)
+	}
+}
+
+// TasksRefreshCallbackQueryHandler 刷新任务列表
+func TasksRefreshCallbackQueryHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	editTasksMessage(bot, update, user.Language)
+}
+
+func editTasksMessage(bot *tgbotapi.BotAPI, update *tgbotapi.Update, language string) {
+	userId := common.ParseCallbackQueryUserId(update)
+	chatID := common.ParseCallbackQueryChatId(update)
+
+	text, replyMarkup := common.TasksMessage(userId, language)
+	editMsg := tgbotapi.NewEditMessageText(chatID, update.CallbackQuery.Message.MessageID, text)
+	editMsg.ReplyMarkup = replyMarkup
+	if _, err := common.SendWithRetry(bot, editMsg); err != nil {
+		log.Println("edit tasks message error", err)
+	}
+}
+
+// parseTaskCallbackQueryData 解析 <action>_m_<infoHash>[_<序号>] 或 <action>_d_<infoHash>_<文件序号>[_<序号>]
+func parseTaskCallbackQueryData(data string, action string) (*taskCallbackData, error) {
+	split := strings.Split(data, "_")
+	if len(split) < 3 || split[0] != action {
+		return nil, errors.New("invalid data")
+	}
+
+	parsed := &taskCallbackData{taskType: split[1], infoHash: split[2]}
+	rest := split[3:]
+	switch parsed.taskType {
+	case common.TaskTypeMagnet:
+	case common.TaskTypeDownload:
+		if len(rest) == 0 {
+			return nil, errors.New("invalid data")
+		}
+		fileIndex, err := strconv.Atoi(rest[0])
+		if err != nil {
+			return nil, err
+		}
+		parsed.fileIndex = fileIndex
+		rest = rest[1:]
+	default:
+		return nil, errors.New("invalid task type")
+	}
+	if len(rest) > 0 {
+		parsed.number = rest[0]
+	}
+	return parsed, nil
+}
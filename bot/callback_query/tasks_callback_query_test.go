@@ -0,0 +1,21 @@
+package callback_query
+
+import "testing"
+
+func TestParseTaskCallbackQueryData(t *testing.T) {
+	data, err := parseTaskCallbackQueryData("taskjump_d_abc_-101_3", "taskjump")
+	if err != nil || data.taskType != "d" || data.infoHash != "abc" || data.fileIndex != -101 || data.number != "3" {
+		t.Fatalf("download jump = %+v, %v", data, err)
+	}
+
+	data, err = parseTaskCallbackQueryData("taskstop_m_abc", "taskstop")
+	if err != nil || data.taskType != "m" || data.infoHash != "abc" || data.number != "" {
+		t.Fatalf("magnet stop = %+v, %v", data, err)
+	}
+
+	for _, invalid := range []string{"taskstop_d_abc", "taskstop_x_abc", "taskjump_m_abc", "taskstop_d_abc_x"} {
+		if _, err := parseTaskCallbackQueryData(invalid, "taskstop"); err == nil {
+			t.Fatalf("%s: expected error", invalid)
+		}
+	}
+}
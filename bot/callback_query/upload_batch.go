@@ -0,0 +1,124 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/telegram"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// uploadBatch 一次发送的文件：单个文件，或最多 telegram.MaxAlbumSize 个图片/视频组成的相册
+type uploadBatch struct {
+	paths  []string
+	botAPI bool // 通过 Bot API 直接发送给用户，否则通过帐号上传到评论区
+}
+
+// planUploadBatches 将连续的图片/视频合并为相册，其余文件单独发送
+// botAPI 为 true 时小于 Bot API 上传限制的文件通过 Bot API 发送，accountLimit 为帐号的单文件上传限制
+func planUploadBatches(paths []string, botAPI bool, accountLimit int64) []uploadBatch {
+	batches := make([]uploadBatch, 0, len(paths))
+	var album *uploadBatch
+	albumSize := int64(0)
+	flush := func() {
+		if album != nil {
+			batches = append(batches, *album)
+			album = nil
+			albumSize = 0
+		}
+	}
+
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil || stat.IsDir() {
+			flush()
+			batches = append(batches, uploadBatch{paths: []string{path}})
+			continue
+		}
+
+		size := stat.Size()
+		viaBotAPI := botAPI && size < botAPIUploadLimit
+		// 超过帐号上传限制的文件需要分卷发送，不能放入相册
+		if albumMediaType(path, size) == "" || (!viaBotAPI && size > accountLimit) {
+			flush()
+			batches = append(batches, uploadBatch{paths: []string{path}, botAPI: viaBotAPI})
+			continue
+		}
+
+		// Bot API 一次请求的上传总大小同样受上传限制
+		if album != nil && (album.botAPI != viaBotAPI ||
+			len(album.paths) >= telegram.MaxAlbumSize ||
+			(viaBotAPI && albumSize+size >= botAPIUploadLimit)) {
+			flush()
+		}
+		if album == nil {
+			album = &uploadBatch{botAPI: viaBotAPI}
+		}
+		album.paths = append(album.paths, path)
+		albumSize += size
+	}
+	flush()
+
+	return batches
+}
+
+// sendBatchByBotAPI 通过 Bot API 将文件发送给用户，再复制到缓存频道消息的评论区，返回评论消息 ID
+// 发送给用户失败时返回 sent 为 false，复制到评论区失败时返回错误
+func sendBatchByBotAPI(bot *tgbotapi.BotAPI, chatID int64, batch uploadBatch, discussionChatID int64, threadMessageID int) (messageIDs []int, sent bool, err error) {
+	if len(batch.paths) == 1 {
+		message, err := sendFileByBotAPI(bot, chatID, batch.paths[0])
+		if err != nil {
+			log.Println("send file by bot api error", err)
+			return nil, false, nil
+		}
+		messageID, err := mirrorToDiscussion(bot, chatID, message.MessageID, discussionChatID, threadMessageID)
+		if err != nil {
+			return nil, true, err
+		}
+		return []int{messageID}, true, nil
+	}
+
+	messages, err := sendAlbumByBotAPI(bot, chatID, batch.paths)
+	if err != nil {
+		log.Println("send album by bot api error", err)
+		return nil, false, nil
+	}
+	messageIDs, err = mirrorAlbumToDiscussion(bot, messages, discussionChatID, threadMessageID)
+	return messageIDs, true, err
+}
+
+// sendBatchByAccount 通过帐号将文件上传到缓存频道消息的评论区，再复制给用户，返回评论消息
+// 上传或复制失败时返回错误
+func sendBatchByAccount(bot *tgbotapi.BotAPI, chatID int64, batch uploadBatch, threadMessageID int, progress func(telegram.UploadProgressParams)) ([]telegram.CommentMessage, error) {
+	var comments []telegram.CommentMessage
+	var err error
+	if len(batch.paths) == 1 {
+		// 大文件会拆分为多个分卷评论
+		comments, err = telegram.SendCommentMessage(batch.paths[0], threadMessageID, progress)
+	} else {
+		comments, err = telegram.SendCommentAlbum(batch.paths, threadMessageID, progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commentChatID := int64(0)
+	messageIDs := make([]int, 0, len(comments))
+	for _, comment := range comments {
+		if comment.MessageID != 0 {
+			commentChatID = comment.ChatID
+			messageIDs = append(messageIDs, comment.MessageID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return nil, errors.New("no comment message sent")
+	}
+	// 批量复制，相册保持分组，复制失败时文件没有发送给用户
+	if err := common.CopyMessages(bot, chatID, commentChatID, messageIDs); err != nil {
+		return nil, fmt.Errorf("copy download comment messages: %w", err)
+	}
+	return comments, nil
+}
@@ -0,0 +1,55 @@
+package callback_query
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanUploadBatches(t *testing.T) {
+	dir := t.TempDir()
+	create := func(name string, size int64) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(path, size); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	paths := []string{}
+	for i := 0; i < 12; i++ {
+		paths = append(paths, create(fmt.Sprintf("%02d.jpg", i), 1024))
+	}
+	paths = append(paths, create("readme.txt", 10))
+	paths = append(paths, create("a.mp4", 1024), create("b.JPG", 1024))
+
+	batches := planUploadBatches(paths, true, 2<<30)
+	sizes := []int{}
+	for _, batch := range batches {
+		if !batch.botAPI {
+			t.Fatalf("small files should be sent by bot api: %v", batch.paths)
+		}
+		sizes = append(sizes, len(batch.paths))
+	}
+	// 12 张图片拆分为 10 + 2，文本文件单独发送，视频和图片合并为相册
+	if fmt.Sprint(sizes) != "[10 2 1 2]" {
+		t.Fatalf("unexpected batches: %v", sizes)
+	}
+
+	// 超过 Bot API 限制的视频通过帐号上传，不与 Bot API 相册合并
+	big := create("big.mp4", botAPIUploadLimit+1)
+	batches = planUploadBatches([]string{paths[0], big, paths[1]}, true, 2<<30)
+	if len(batches) != 3 || batches[1].botAPI {
+		t.Fatalf("unexpected batches for big video: %+v", batches)
+	}
+
+	// 超过帐号上传限制的文件需要分卷，单独发送
+	batches = planUploadBatches([]string{big, big}, false, botAPIUploadLimit)
+	if len(batches) != 2 {
+		t.Fatalf("files over account limit should not be grouped: %+v", batches)
+	}
+}
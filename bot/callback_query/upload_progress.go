@@ -0,0 +1,159 @@
+package callback_query
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/telegram"
+	"bt-bot/utils"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 编辑进度消息的最小间隔，避免触发 Bot API 编辑消息的频率限制
+const progressEditInterval = 5 * time.Second
+
+// uploadProgressReporter 将上传进度更新到用户的进度消息
+type uploadProgressReporter struct {
+	mu sync.Mutex
+
+	bot           *tgbotapi.BotAPI
+	chatID        int64
+	messageID     int
+	infoHash      string
+	downloadFiles string
+	language      string
+
+	fileNumber int
+	fileCount  int
+
+	lastEdit     time.Time
+	lastUploaded int64
+	lastSample   time.Time
+	speed        int64
+
+	// 编辑消息在后台发送，避免 429 退避阻塞上传；发送中只保留最新的消息
+	sending bool
+	pending string
+	stopped bool
+	sends   sync.WaitGroup
+}
+
+func newUploadProgressReporter(bot *tgbotapi.BotAPI, chatID int64, messageID int, infoHash string, downloadFiles string, language string) *uploadProgressReporter {
+	return &uploadProgressReporter{
+		bot:           bot,
+		chatID:        chatID,
+		messageID:     messageID,
+		infoHash:      infoHash,
+		downloadFiles: downloadFiles,
+		language:      language,
+	}
+}
+
+// Start 显示发送中消息，上传开始前进度为 0
+func (r *uploadProgressReporter) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.edit(telegram.UploadProgressParams{})
+}
+
+// StartFile 开始上传第 number 个文件（共 count 个），立即刷新一次消息
+func (r *uploadProgressReporter) StartFile(number int, count int, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fileNumber = number
+	r.fileCount = count
+	r.lastUploaded = 0
+	r.lastSample = time.Now()
+	r.speed = 0
+	r.edit(telegram.UploadProgressParams{FileName: filepath.Base(path)})
+}
+
+// Progress 接收上传进度，按 progressEditInterval 节流更新消息
+func (r *uploadProgressReporter) Progress(params telegram.UploadProgressParams) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	// 大文件分卷上传时，每个分卷的进度从 0 开始
+	if params.Uploaded < r.lastUploaded {
+		r.lastUploaded = 0
+		r.lastSample = now
+	}
+	if now.Sub(r.lastEdit) < progressEditInterval {
+		return
+	}
+
+	// 以两次刷新之间的平均速度作为当前速度
+	if elapsed := now.Sub(r.lastSample); elapsed > 0 {
+		r.speed = int64(float64(params.Uploaded-r.lastUploaded) / elapsed.Seconds())
+	}
+	r.lastUploaded = params.Uploaded
+	r.lastSample = now
+
+	r.edit(params)
+}
+
+// Stop 停止更新进度并等待发送中的编辑完成，之后可以安全地编辑为最终结果
+func (r *uploadProgressReporter) Stop() {
+	r.mu.Lock()
+	r.stopped = true
+	r.pending = ""
+	r.mu.Unlock()
+
+	r.sends.Wait()
+}
+
+// edit 编辑进度消息，调用者需持有 r.mu
+func (r *uploadProgressReporter) edit(params telegram.UploadProgressParams) {
+	if r.stopped {
+		return
+	}
+	r.lastEdit = time.Now()
+
+	message := i18n.Text(i18n.DownloadSendFileMessageCode, r.language)
+	message = i18n.Replace(message, map[string]string{
+		i18n.DownloadMessagePlaceholderMagnet:         r.infoHash,
+		i18n.DownloadMessagePlaceholderDownloadFiles:  r.downloadFiles,
+		i18n.DownloadMessagePlaceholderFileNumber:     strconv.Itoa(r.fileNumber),
+		i18n.DownloadMessagePlaceholderFileCount:      strconv.Itoa(r.fileCount),
+		i18n.DownloadMessagePlaceholderPercent:        utils.FormatPercentage(params.Uploaded, params.Total),
+		i18n.DownloadMessagePlaceholderBytesCompleted: utils.FormatBytesToSizeString(params.Uploaded),
+		i18n.DownloadMessagePlaceholderTotalBytes:     utils.FormatBytesToSizeString(max(params.Total, 0)),
+		i18n.DownloadMessagePlaceholderUploadFile:     params.FileName,
+		i18n.DownloadMessagePlaceholderSpeed:          utils.FormatSpeed(r.speed),
+		i18n.DownloadMessagePlaceholderETA:            utils.FormatETA(params.Total-params.Uploaded, r.speed),
+	})
+
+	r.pending = message
+	if r.sending {
+		return
+	}
+	r.sending = true
+	r.sends.Add(1)
+	go r.send()
+}
+
+// send 依次发送最新的进度消息，直到没有新的消息
+func (r *uploadProgressReporter) send() {
+	defer r.sends.Done()
+
+	for {
+		r.mu.Lock()
+		message := r.pending
+		r.pending = ""
+		if message == "" {
+			r.sending = false
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+
+		common.SendWithRetry(r.bot, tgbotapi.NewEditMessageText(r.chatID, r.messageID, message))
+	}
+}
@@ -0,0 +1,250 @@
+package command
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/database/model"
+	"bt-bot/utils"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 广播消息之间的间隔，避免触发 Telegram 的频率限制
+const broadcastInterval = 50 * time.Millisecond
+
+// GrantCommand 升级为高级用户：/grant <目标> premium <天数>
+func GrantCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandGrant, func(user *model.User, args []string) error {
+		if len(args) != 2 || args[0] != model.PermissionsTypePremium {
+			return errAdminUsage
+		}
+		days, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errAdminUsage
+		}
+		_, err = common.GrantPremium(user, days)
+		return err
+	})
+}
+
+// RevokeCommand 恢复为普通用户：/revoke <目标>
+func RevokeCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandRevoke, func(user *model.User, args []string) error {
+		_, err := common.RevokePremium(user)
+		return err
+	})
+}
+
+// BanCommand 禁止使用：/ban <目标> [原因]
+func BanCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandBan, func(user *model.User, args []string) error {
+		return common.SetUserBanned(user, true, strings.Join(args, " "))
+	})
+}
+
+// UnbanCommand 解除禁止：/unban <目标>
+func UnbanCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandUnban, func(user *model.User, args []string) error {
+		return common.SetUserBanned(user, false, "")
+	})
+}
+
+// ResetQuotaCommand 重置今日下载次数：/resetquota <目标>
+func ResetQuotaCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandResetQuota, func(user *model.User, args []string) error {
+		_, err := common.ResetDailyDownloadQuantity(user)
+		return err
+	})
+}
+
+// UserInfoCommand 查看用户信息：/userinfo <目标>
+func UserInfoCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminUserCommand(bot, update, CommandUserInfo, nil)
+}
+
+// StatsCommand 查看运行统计：/stats
+func StatsCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+	language := adminLanguage(adminId)
+
+	stats, err := common.Stats()
+	writeAuditLog(adminId, CommandStats, "", "", err)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+
+	message := i18n.Replace(i18n.Text(i18n.AdminStatsMessageCode, language), map[string]string{
+		i18n.AdminMessagePlaceholderUsers:          strconv.FormatInt(stats.Users, 10),
+		i18n.AdminMessagePlaceholderPremiumUsers:   strconv.FormatInt(stats.PremiumUsers, 10),
+		i18n.AdminMessagePlaceholderBannedUsers:    strconv.FormatInt(stats.BannedUsers, 10),
+		i18n.AdminMessagePlaceholderTorrents:       strconv.FormatInt(stats.Torrents, 10),
+		i18n.AdminMessagePlaceholderCachedFiles:    strconv.FormatInt(stats.CachedFiles, 10),
+		i18n.AdminMessagePlaceholderActiveJobs:     strconv.FormatInt(stats.ActiveJobs, 10),
+		i18n.AdminMessagePlaceholderDownloadsToday: strconv.FormatInt(stats.DownloadsToday, 10),
+		i18n.AdminMessagePlaceholderDownloads:      strconv.FormatInt(stats.Downloads, 10),
+	})
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, message))
+}
+
+// BroadcastCommand 向所有未被禁止的用户发送消息：/broadcast <内容>
+// 发送在后台进行，完成后回复成功和失败的数量
+func BroadcastCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	adminId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+	language := adminLanguage(adminId)
+
+	text := strings.TrimSpace(update.Message.CommandArguments())
+	if text == "" {
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Text(i18n.AdminUsageMessageCode, language)))
+		return
+	}
+
+	userIDs, err := common.BroadcastUserIDs()
+	if err != nil {
+		writeAuditLog(adminId, CommandBroadcast, "", text, err)
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Replace(i18n.Text(i18n.AdminBroadcastStartCode, language), map[string]string{
+		i18n.AdminMessagePlaceholderCount: strconv.Itoa(len(userIDs)),
+	})))
+
+	go func() {
+		sent, failed := 0, 0
+		for _, userID := range userIDs {
+			if _, err := common.SendWithRetry(bot, tgbotapi.NewMessage(userID, text)); err != nil {
+				log.Println("Send broadcast message error:", userID, err)
+				failed++
+			} else {
+				sent++
+			}
+			time.Sleep(broadcastInterval)
+		}
+
+		var broadcastErr error
+		if failed > 0 {
+			broadcastErr = errors.New("sent " + strconv.Itoa(sent) + ", failed " + strconv.Itoa(failed))
+		}
+		writeAuditLog(adminId, CommandBroadcast, "", text, broadcastErr)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Replace(i18n.Text(i18n.AdminBroadcastDoneCode, language), map[string]string{
+			i18n.AdminMessagePlaceholderSent:   strconv.Itoa(sent),
+			i18n.AdminMessagePlaceholderFailed: strconv.Itoa(failed),
+		})))
+	}()
+}
+
+// errAdminUsage 参数不正确，回复管理命令的用法
+var errAdminUsage = errors.New("invalid arguments")
+
+// adminUserCommand 解析目标用户并执行操作，记录审计日志后回复用户信息
+// action 为 nil 时只查看用户信息
+func adminUserCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update, command string, action func(user *model.User, args []string) error) {
+	adminId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+	language := adminLanguage(adminId)
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Text(i18n.AdminUsageMessageCode, language)))
+		return
+	}
+	target, detail := args[0], strings.Join(args[1:], " ")
+
+	user, err := common.FindUser(target)
+	if err != nil {
+		writeAuditLog(adminId, command, target, detail, err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Replace(i18n.Text(i18n.AdminUserNotFoundMessageCode, language), map[string]string{
+			i18n.AdminMessagePlaceholderTarget: target,
+		})))
+		return
+	}
+
+	if action != nil {
+		err = action(user, args[1:])
+	}
+	writeAuditLog(adminId, command, user.UUID, detail, err)
+	if errors.Is(err, errAdminUsage) {
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Text(i18n.AdminUsageMessageCode, language)))
+		return
+	}
+	if err != nil {
+		log.Println("admin command error:", command, err)
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+
+	userInfo, err := adminUserInfo(user, language)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+	message := userInfo
+	if action != nil {
+		message = i18n.Replace(i18n.Text(i18n.AdminDoneMessageCode, language), map[string]string{
+			i18n.AdminMessagePlaceholderAction:   command,
+			i18n.AdminMessagePlaceholderUserInfo: userInfo,
+		})
+	}
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, message))
+}
+
+// adminUserInfo 用户信息，包括绑定的用户 ID、权限和禁止状态
+func adminUserInfo(user *model.User, language string) (string, error) {
+	permissions, err := common.UserPermissions(user)
+	if err != nil {
+		return "", err
+	}
+	userIDs, err := common.UserIDs(user)
+	if err != nil {
+		return "", err
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		ids = append(ids, strconv.FormatInt(userID, 10))
+	}
+	premiumExpireAt := "-"
+	if permissions.Type == model.PermissionsTypePremium && permissions.PremiumExpireAt > 0 {
+		premiumExpireAt = time.Unix(permissions.PremiumExpireAt, 0).Format("2006-01-02 15:04")
+	}
+	banned := "❌"
+	if user.Banned {
+		banned = "✅"
+	}
+
+	return i18n.Replace(i18n.Text(i18n.AdminUserInfoMessageCode, language), map[string]string{
+		i18n.AdminMessagePlaceholderUUID:                  user.UUID,
+		i18n.AdminMessagePlaceholderUserIDs:               strings.Join(ids, ", "),
+		i18n.AdminMessagePlaceholderLanguage:              user.Language,
+		i18n.AdminMessagePlaceholderType:                  permissions.Type,
+		i18n.AdminMessagePlaceholderPremiumExpireAt:       premiumExpireAt,
+		i18n.AdminMessagePlaceholderDailyDownloadRemain:   strconv.Itoa(permissions.DailyDownloadRemain),
+		i18n.AdminMessagePlaceholderDailyDownloadQuantity: strconv.Itoa(permissions.DailyDownloadQuantity),
+		i18n.AdminMessagePlaceholderAsyncDownloadQuantity: strconv.Itoa(permissions.AsyncDownloadQuantity),
+		i18n.AdminMessagePlaceholderFileDownloadSize:      utils.FormatBytesToSizeString(permissions.FileDownloadSize),
+		i18n.AdminMessagePlaceholderBanned:                banned,
+		i18n.AdminMessagePlaceholderReason:                user.BanReason,
+	}), nil
+}
+
+// adminLanguage 管理员自己的语言设置
+func adminLanguage(adminId int64) string {
+	user, err := common.User(adminId)
+	if err != nil {
+		return i18n.LangZH
+	}
+	return user.Language
+}
+
+func writeAuditLog(adminId int64, action string, target string, detail string, actionErr error) {
+	if err := common.WriteAuditLog(adminId, action, target, detail, actionErr); err != nil {
+		log.Println("write audit log error:", err)
+	}
+}
@@ -12,6 +12,19 @@ const (
 	CommandMagnet    = "magnet"
 	CommandSelf      = "self"
 	CommandRecommend = "recommend"
+	CommandFilter    = "filter"
+	CommandTasks     = "tasks"
+	CommandHistory   = "history"
+
+	// 管理命令
+	CommandGrant      = "grant"
+	CommandRevoke     = "revoke"
+	CommandBan        = "ban"
+	CommandUnban      = "unban"
+	CommandResetQuota = "resetquota"
+	CommandUserInfo   = "userinfo"
+	CommandStats      = "stats"
+	CommandBroadcast  = "broadcast"
 )
 
 func CommandHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
@@ -34,6 +47,28 @@ func CommandHandler(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 			middleware.MagnetMiddleWare(MagnetCommand)(bot, update)
 		case CommandRecommend:
 			RecommendCommand(bot, update)
+		case CommandFilter:
+			FilterCommand(bot, update)
+		case CommandTasks:
+			TasksCommand(bot, update)
+		case CommandHistory:
+			HistoryCommand(bot, update)
+		case CommandGrant:
+			middleware.AdminMiddleWare(GrantCommand)(bot, update)
+		case CommandRevoke:
+			middleware.AdminMiddleWare(RevokeCommand)(bot, update)
+		case CommandBan:
+			middleware.AdminMiddleWare(BanCommand)(bot, update)
+		case CommandUnban:
+			middleware.AdminMiddleWare(UnbanCommand)(bot, update)
+		case CommandResetQuota:
+			middleware.AdminMiddleWare(ResetQuotaCommand)(bot, update)
+		case CommandUserInfo:
+			middleware.AdminMiddleWare(UserInfoCommand)(bot, update)
+		case CommandStats:
+			middleware.AdminMiddleWare(StatsCommand)(bot, update)
+		case CommandBroadcast:
+			middleware.AdminMiddleWare(BroadcastCommand)(bot, update)
 		}
 	}
 }
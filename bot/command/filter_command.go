@@ -0,0 +1,109 @@
+package command
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/database/model"
+	"bt-bot/torrent"
+	"bt-bot/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 筛选结果中最多列出的文件数量，下载按钮包含全部结果
+const filterListLimit = 20
+
+// FilterCommand 按名称、分类和大小筛选已解析种子的文件：/filter <infohash> <条件...>
+// 结果保存为一个文件选择，点击按钮作为一个下载任务
+func FilterCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 2 {
+		sendFilterUsage(bot, chatID, user.Language, "")
+		return
+	}
+
+	filter, err := common.ParseFileFilter(args[1:])
+	if err != nil {
+		sendFilterUsage(bot, chatID, user.Language, "❌ "+err.Error())
+		return
+	}
+
+	infoHash := strings.ToLower(args[0])
+	torrentInfo, err := common.GetTorrentInfo(infoHash)
+	if err != nil {
+		log.Println("get torrent info error", err)
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Text(i18n.FilterTorrentNotFoundMessageCode, user.Language)))
+		return
+	}
+
+	files := common.FilterFiles(torrentInfo, filter)
+	if len(files) == 0 {
+		common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, i18n.Text(i18n.FilterNoMatchMessageCode, user.Language)))
+		return
+	}
+
+	indexes := make([]int, 0, len(files))
+	length := int64(0)
+	for _, file := range files {
+		indexes = append(indexes, file.FileIndex)
+		length += file.Length
+	}
+	selection, err := common.SaveFileSelection(infoHash, "Filter: "+strings.Join(args[1:], " "), indexes)
+	if err != nil {
+		log.Println("save file selection error", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	message := i18n.Replace(i18n.Text(i18n.FilterResultMessageCode, user.Language), map[string]string{
+		i18n.FilterMessagePlaceholderFileCount: strconv.Itoa(len(files)),
+		i18n.FilterMessagePlaceholderFileSize:  utils.FormatBytesToSizeString(length),
+		i18n.FilterMessagePlaceholderFileList:  filterFileList(files),
+	})
+	buttonText := i18n.Replace(i18n.Text(i18n.ButtonDownloadFilteredCode, user.Language), map[string]string{
+		i18n.FilterMessagePlaceholderFileCount: strconv.Itoa(len(files)),
+		i18n.FilterMessagePlaceholderFileSize:  utils.FormatBytesToSizeString(length),
+	})
+	reply := tgbotapi.NewMessage(chatID, message)
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("file_%s_%d", infoHash, model.SelectionFileIndex(selection.ID))),
+		),
+	)
+	if _, err := common.SendWithRetry(bot, reply); err != nil {
+		log.Println("Send filter message error:", err)
+	}
+}
+
+func sendFilterUsage(bot *tgbotapi.BotAPI, chatID int64, language string, errorMessage string) {
+	message := i18n.Replace(i18n.Text(i18n.FilterUsageMessageCode, language), map[string]string{
+		i18n.FilterMessagePlaceholderCategory: strings.Join(torrent.FileCategories(), ", "),
+		i18n.FilterMessagePlaceholderError:    errorMessage,
+	})
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, message))
+}
+
+func filterFileList(files []model.TorrentFile) string {
+	lines := make([]string, 0, min(len(files), filterListLimit)+1)
+	for i, file := range files {
+		if i == filterListLimit {
+			lines = append(lines, fmt.Sprintf("… +%d", len(files)-filterListLimit))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s %d. %s (%s)", common.EmojifyFilename(file.DisplayPath()), i+1, file.DisplayPath(), utils.FormatBytesToSizeString(file.Length)))
+	}
+	return strings.Join(lines, "\n")
+}
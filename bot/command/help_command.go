@@ -21,8 +21,8 @@ func HelpCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 
 	// 生成帮助消息
 	message := i18n.Replace(i18n.Text(i18n.HelpMessageCode, user.Language), map[string]string{
-		i18n.HelpMessagePlaceholderDownloadChannel: "@tgqpXOZ2tzXN",
-		i18n.HelpMessagePlaceholderHelpChannel:     "@bt1bot1channel",
+		i18n.HelpMessagePlaceholderDownloadChannel: common.DownloadChannel(),
+		i18n.HelpMessagePlaceholderHelpChannel:     common.HelpChannel(),
 	})
 
 	// 创建帮助消息
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"bt-bot/bot/common"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HistoryCommand 分页显示用户的下载记录
+func HistoryCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	text, replyMarkup, err := common.HistoryMessage(user, 0, user.Language)
+	if err != nil {
+		log.Println("create history message error:", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+	reply := tgbotapi.NewMessage(chatID, text)
+	if replyMarkup != nil {
+		reply.ReplyMarkup = replyMarkup
+	}
+	if _, err := common.SendWithRetry(bot, reply); err != nil {
+		log.Println("Send history message error:", err)
+	}
+}
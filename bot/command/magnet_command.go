@@ -5,12 +5,10 @@ import (
 	"bt-bot/bot/i18n"
 	"bt-bot/database/model"
 	"bt-bot/torrent"
-	"bt-bot/utils"
 	"context"
 	"fmt"
 	"log"
 	"strconv"
-	"strings"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -52,7 +50,13 @@ func MagnetCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 	sentMsg, _ := common.SendWithRetry(bot, processingMsg)
 
 	ctx, cancel := context.WithCancel(context.Background())
-	torrent.SetTorrentCancel(infoHash, userID, cancel)
+	torrent.SetTorrentCancel(torrent.MagnetTask{
+		InfoHash:  infoHash,
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: sentMsg.MessageID,
+		StartedAt: startTime,
+	}, cancel)
 	defer torrent.RemoveTorrentCancel(infoHash, userID)
 
 	var info *model.Torrent
@@ -101,41 +105,21 @@ func MagnetCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 		return
 	}
 
-	// 获取文件列表
-	const maxButtons = 48
-	files := info.Files
-	filesFirstPage := files[:min(maxButtons, len(files))]
+	sendMagnetSuccessMessage(bot, chatID, sentMsg.MessageID, info, user.Language)
+}
 
-	// 发送第一页成功消息
-	successMessage := i18n.Text(i18n.MagnetSuccessMessageCode, user.Language)
-	successMessage = i18n.Replace(successMessage, map[string]string{
-		i18n.MagnetMessagePlaceholderMagnetLink: magnetLink,
-		i18n.MagnetMessagePlaceholderFileName:   info.Name,
-		i18n.MagnetMessagePlaceholderFileSize:   utils.FormatBytesToSizeString(info.TotalLength()),
-		i18n.MagnetMessagePlaceholderFileCount:  strconv.Itoa(len(filesFirstPage)),
-		i18n.MagnetMessagePlaceholderFileList:   strings.Join(fileList(filesFirstPage), "\n"),
-	})
-	editMsg := tgbotapi.NewEditMessageText(chatID, sentMsg.MessageID, successMessage)
-	replyMarkup := createFileButtons(filesFirstPage, info.InfoHash)
-	replyMarkup.InlineKeyboard = append([][]tgbotapi.InlineKeyboardButton{allFileButton(info.InfoHash)}, replyMarkup.InlineKeyboard...)
+// sendMagnetSuccessMessage 编辑解析中消息为种子根文件夹的浏览消息
+func sendMagnetSuccessMessage(bot *tgbotapi.BotAPI, chatID int64, messageID int, info *model.Torrent, language string) {
+	text, replyMarkup, err := common.FileTreeMessage(info, common.FileTreeView{}, language)
+	if err != nil {
+		log.Println("create file tree message error:", err)
+		common.SendErrorMessage(bot, chatID, language, err)
+		return
+	}
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
 	editMsg.ReplyMarkup = replyMarkup
-	common.SendWithRetry(bot, editMsg)
-
-	// 发送后续页成功消息
-	for i := maxButtons; i < len(files); i += maxButtons {
-		filesPage := files[i:min(i+maxButtons, len(files))]
-		successMessage = i18n.Text(i18n.MagnetSuccessMessageCode, user.Language)
-		successMessage = i18n.Replace(successMessage, map[string]string{
-			i18n.MagnetMessagePlaceholderMagnetLink: magnetLink,
-			i18n.MagnetMessagePlaceholderFileName:   info.Name,
-			i18n.MagnetMessagePlaceholderFileSize:   utils.FormatBytesToSizeString(info.TotalLength()),
-			i18n.MagnetMessagePlaceholderFileCount:  strconv.Itoa(len(filesPage)),
-			i18n.MagnetMessagePlaceholderFileList:   strings.Join(fileList(filesPage), "\n"),
-		})
-
-		message := tgbotapi.NewMessage(chatID, successMessage)
-		message.ReplyMarkup = createFileButtons(filesPage, info.InfoHash)
-		common.SendWithRetry(bot, message)
+	if _, err := common.SendWithRetry(bot, editMsg); err != nil {
+		log.Println("Send magnet success message error:", err)
 	}
 }
 
@@ -171,123 +155,16 @@ func parseMagnetLink(ctx context.Context, magnetLink string) (*model.Torrent, er
 		if err != nil {
 			log.Panicln("common.SaveTorrentInfo err: ", err)
 		}
+		metaInfo := info.Metainfo()
+		if err := common.SaveTorrentMetaInfo(infoHash, &metaInfo); err != nil {
+			log.Println("common.SaveTorrentMetaInfo err: ", err)
+		}
 		info_ = *torrentInfo
 	}
 
 	return &info_, nil
 }
 
-func fileList(files []model.TorrentFile) []string {
-	fileList := make([]string, 0)
-	for _, file := range files {
-		path := file.Path
-		if len(file.PathUtf8) > 0 {
-			path = file.PathUtf8
-		}
-		fileLine := fmt.Sprintf("%s %d.%s (%s)", emojifyFilename(path), file.FileIndex+1, path, utils.FormatBytesToSizeString(file.Length))
-		fileList = append(fileList, fileLine)
-	}
-	return fileList
-}
-
-func allFileButton(infoHash string) []tgbotapi.InlineKeyboardButton {
-	button := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("All files", "file_"+infoHash+"_-1"),
-		tgbotapi.NewInlineKeyboardButtonData("All images", "file_"+infoHash+"_-2"),
-		tgbotapi.NewInlineKeyboardButtonData("All videos", "file_"+infoHash+"_-3"),
-	}
-	return button
-}
-
-// createFileButtons 创建文件按钮（多按钮同行）
-func createFileButtons(files []model.TorrentFile, infoHash string) *tgbotapi.InlineKeyboardMarkup {
-	const buttonsPerRow = 8 // 每行显示的按钮数
-
-	var buttons [][]tgbotapi.InlineKeyboardButton
-
-	// 文件按钮，每行多个按钮
-	row := []tgbotapi.InlineKeyboardButton{}
-	for i := 0; i < len(files); i++ {
-		file := files[i]
-
-		buttonText := fmt.Sprintf("%d", file.FileIndex+1)
-		callbackData := fmt.Sprintf("file_%s_%d", infoHash, file.FileIndex)
-		button := tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData)
-		row = append(row, button)
-
-		// 每buttonsPerRow个按钮一行
-		if len(row) == buttonsPerRow {
-			buttons = append(buttons, row)
-			row = []tgbotapi.InlineKeyboardButton{}
-		}
-	}
-	// 有剩余按钮未满一行
-	if len(row) > 0 {
-		buttons = append(buttons, row)
-	}
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(buttons...)
-	return &keyboard
-}
-
-func emojifyFilename(filename string) string {
-	// 根据文件后缀返回带有 emoji 的文件名
-	extToEmoji := map[string]string{
-		".mp4":     "🎬",
-		".mkv":     "🎥",
-		".avi":     "📽️",
-		".mov":     "🎞️",
-		".ts":      "📼",
-		".mp3":     "🎵",
-		".flac":    "🎶",
-		".wav":     "🔊",
-		".ape":     "🎼",
-		".aac":     "🎧",
-		".ogg":     "🎶",
-		".jpg":     "🖼️",
-		".jpeg":    "🖼️",
-		".png":     "📸",
-		".gif":     "🎞️",
-		".webp":    "🌆",
-		".bmp":     "🖼️",
-		".zip":     "🗜️",
-		".rar":     "🗂️",
-		".7z":      "📦",
-		".tar":     "📦",
-		".gz":      "🗄️",
-		".pdf":     "📑",
-		".epub":    "📚",
-		".txt":     "📄",
-		".doc":     "📝",
-		".docx":    "📝",
-		".ppt":     "📊",
-		".pptx":    "📊",
-		".xls":     "📈",
-		".xlsx":    "📈",
-		".apk":     "🤖",
-		".exe":     "🖥️",
-		".iso":     "💿",
-		".torrent": "🧲",
-	}
-
-	ext := ""
-	for i := len(filename) - 1; i >= 0; i-- {
-		if filename[i] == '.' {
-			ext = filename[i:]
-			break
-		}
-	}
-	emoji := ""
-	if val, ok := extToEmoji[ext]; ok {
-		emoji = val
-	}
-	if emoji != "" {
-		return emoji
-	} else {
-		return "📄"
-	}
-}
-
 func stopMagnetReplyMarkup(infoHash string, userId int64, language string) *tgbotapi.InlineKeyboardMarkup {
 	data := "stop_magnet_" + infoHash + "_" + strconv.FormatInt(userId, 10)
 
@@ -3,6 +3,7 @@ package command
 import (
 	"bt-bot/bot/common"
 	"bt-bot/bot/i18n"
+	middleware "bt-bot/bot/middle_ware"
 	"bt-bot/utils"
 	"log"
 	"strconv"
@@ -36,6 +37,7 @@ func SelfCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 		i18n.SelfMessagePlaceholderLanguage:              user.Language,
 		i18n.SelfMessagePlaceholderDailyDownloadRemain:   strconv.Itoa(permissions.DailyDownloadRemain),
 		i18n.SelfMessagePlaceholderAsyncDownloadQuantity: strconv.Itoa(permissions.AsyncDownloadQuantity),
+		i18n.SelfMessagePlaceholderAsyncDownloadInUse:    strconv.Itoa(middleware.DownloadSlotsInUse(user.UUID)),
 		i18n.SelfMessagePlaceholderDailyDownloadQuantity: strconv.Itoa(permissions.DailyDownloadQuantity),
 		i18n.SelfMessagePlaceholderFileDownloadSize:      utils.FormatBytesToSizeString(permissions.FileDownloadSize),
 	})
@@ -21,8 +21,8 @@ func StartCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 
 	message := i18n.Replace(i18n.Text(i18n.StartMessageCode, user.Language), map[string]string{
 		i18n.StartMessagePlaceholderUserName:           userName,
-		i18n.StartMessagePlaceholderDownloadChannel:    "@tgqpXOZ2tzXN",
-		i18n.StartMessagePlaceholderHelpChannel:        "@bt1bot1channel",
+		i18n.StartMessagePlaceholderDownloadChannel:    common.DownloadChannel(),
+		i18n.StartMessagePlaceholderHelpChannel:        common.HelpChannel(),
 		i18n.StartMessagePlaceholderCooperationContact: "@IIAlbertEinsteinII",
 		i18n.StartMessagePlaceholderGroupChannel:       GroupChannel(),
 		i18n.StartMessagePlaceholderSearchWebsite:      SearchWebsite(),
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"bt-bot/bot/common"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TasksCommand 列出用户正在进行的解析和下载任务
+func TasksCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	userId := common.ParseUserId(update)
+	chatID := common.ParseMessageChatId(update)
+
+	user, err := common.User(userId)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	text, replyMarkup := common.TasksMessage(userId, user.Language)
+	reply := tgbotapi.NewMessage(chatID, text)
+	reply.ReplyMarkup = replyMarkup
+	if _, err := common.SendWithRetry(bot, reply); err != nil {
+		log.Println("Send tasks message error:", err)
+	}
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+	"bt-bot/torrent"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 种子文件大小上限，Bot API getFile 最大支持 20MB
+const maxTorrentFileSize = 20 * 1024 * 1024
+
+// TorrentFileCommand 解析用户上传的 .torrent 文件
+func TorrentFileCommand(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+	userID := msg.From.ID
+	document := msg.Document
+
+	user, err := common.User(userID)
+	if err != nil {
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+
+	// 下载种子文件
+	data, err := downloadTorrentFile(bot, document)
+	if err != nil {
+		log.Println("download torrent file error:", err)
+		sendTorrentFileInvalidMessage(bot, chatID, document.FileName, err, user.Language)
+		return
+	}
+
+	// 解析种子文件
+	metaInfo, info, err := torrent.LoadTorrentFile(data)
+	if err != nil {
+		log.Println("load torrent file error:", err)
+		sendTorrentFileInvalidMessage(bot, chatID, document.FileName, err, user.Language)
+		return
+	}
+	infoHash := metaInfo.HashInfoBytes().HexString()
+	magnetLink := "magnet:?xt=urn:btih:" + infoHash
+
+	// 发送解析中消息
+	processingMessage := i18n.Text(i18n.MagnetProcessingMessageCode, user.Language)
+	processingMessage = i18n.Replace(processingMessage, map[string]string{
+		i18n.MagnetMessagePlaceholderMagnetLink:  magnetLink,
+		i18n.MagnetMessagePlaceholderElapsedTime: "--:--:--",
+	})
+	sentMsg, err := common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, processingMessage))
+	if err != nil {
+		log.Println("Send torrent file processing message error:", err)
+		return
+	}
+
+	// 保存种子信息
+	torrentInfo, err := common.SaveTorrentInfo(infoHash, info)
+	if err != nil {
+		log.Println("common.SaveTorrentInfo err: ", err)
+		common.SendErrorMessage(bot, chatID, user.Language, err)
+		return
+	}
+	if err := common.SaveTorrentMetaInfo(infoHash, metaInfo); err != nil {
+		log.Println("common.SaveTorrentMetaInfo err: ", err)
+	}
+
+	sendMagnetSuccessMessage(bot, chatID, sentMsg.MessageID, torrentInfo, user.Language)
+}
+
+func downloadTorrentFile(bot *tgbotapi.BotAPI, document *tgbotapi.Document) ([]byte, error) {
+	if document.FileSize > maxTorrentFileSize {
+		return nil, errors.New("torrent file too large")
+	}
+
+	url, err := bot.GetFileDirectURL(document.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download torrent file failed: %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxTorrentFileSize))
+}
+
+func sendTorrentFileInvalidMessage(bot *tgbotapi.BotAPI, chatID int64, fileName string, err error, language string) {
+	message := i18n.Text(i18n.TorrentFileInvalidMessageCode, language)
+	message = i18n.Replace(message, map[string]string{
+		i18n.TorrentFileMessagePlaceholderErrorMessage: err.Error(),
+		i18n.TorrentFileMessagePlaceholderFileName:     fileName,
+	})
+	common.SendWithRetry(bot, tgbotapi.NewMessage(chatID, message))
+}
@@ -0,0 +1,224 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 管理员的 Telegram 用户 ID，启动时由配置文件设置
+var (
+	admins      = make(map[int64]bool)
+	adminsMutex sync.RWMutex
+)
+
+// SetAdmins 设置管理员
+func SetAdmins(userIDs []int64) {
+	adminsMutex.Lock()
+	defer adminsMutex.Unlock()
+
+	admins = make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		admins[userID] = true
+	}
+}
+
+// IsAdmin 判断用户是否为管理员
+func IsAdmin(userID int64) bool {
+	adminsMutex.RLock()
+	defer adminsMutex.RUnlock()
+
+	return admins[userID]
+}
+
+// FindUser 根据 Telegram 用户 ID 或用户 UUID 查找已有用户，不会创建新用户
+func FindUser(target string) (*model.User, error) {
+	if userID, err := strconv.ParseInt(target, 10, 64); err == nil {
+		uuid, ok, err := userUUID(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("user not found")
+		}
+		target = uuid
+	}
+	return user(target)
+}
+
+// UserPermissions 用户的权限
+func UserPermissions(user *model.User) (*model.Permissions, error) {
+	return permissions(user.Premium)
+}
+
+// IsBanned 判断用户是否被禁止使用，没有记录的新用户不会被禁止
+func IsBanned(userID int64) bool {
+	uuid, ok, err := userUUID(userID)
+	if err != nil || !ok {
+		return false
+	}
+	user, err := user(uuid)
+	if err != nil {
+		return false
+	}
+	return user.Banned
+}
+
+// GrantPremium 升级为高级用户 days 天，已是高级用户且未到期时从到期时间顺延
+// 不过期的高级用户不做修改，今日剩余下载次数不变，需要时使用 ResetDailyDownloadQuantity
+func GrantPremium(user *model.User, days int) (*model.Permissions, error) {
+	if days <= 0 {
+		return nil, errors.New("days must be greater than 0")
+	}
+	permissions, err := permissions(user.Premium)
+	if err != nil {
+		return nil, err
+	}
+	if permissions.Type == model.PermissionsTypePremium && permissions.PremiumExpireAt == 0 {
+		return nil, errors.New("user is already a premium user without expiry")
+	}
+
+	start := time.Now()
+	if permissions.Type == model.PermissionsTypePremium && permissions.PremiumExpireAt > start.Unix() {
+		start = time.Unix(permissions.PremiumExpireAt, 0)
+	}
+	applyPermissionsLevel(permissions, model.PremiumPermissions)
+	permissions.PremiumExpireAt = start.AddDate(0, 0, days).Unix()
+	if err := setPermissions(permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// RevokePremium 恢复为普通用户
+func RevokePremium(user *model.User) (*model.Permissions, error) {
+	permissions, err := permissions(user.Premium)
+	if err != nil {
+		return nil, err
+	}
+	downgradePermissions(permissions)
+	if err := setPermissions(permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ResetDailyDownloadQuantity 重置用户今日的下载次数
+func ResetDailyDownloadQuantity(user *model.User) (*model.Permissions, error) {
+	permissions, err := permissions(user.Premium)
+	if err != nil {
+		return nil, err
+	}
+	permissions.DailyDownloadRemain = permissions.DailyDownloadQuantity
+	permissions.DailyDownloadDate = time.Now().Truncate(24 * time.Hour).Unix()
+	if err := setPermissions(permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// SetUserBanned 禁止或解除禁止用户
+func SetUserBanned(user *model.User, banned bool, reason string) error {
+	if !banned {
+		reason = ""
+	}
+	user.Banned = banned
+	user.BanReason = reason
+	return database.DB.Model(user).Updates(map[string]any{
+		"banned":     banned,
+		"ban_reason": reason,
+	}).Error
+}
+
+// UserIDs 用户绑定的所有 Telegram 用户 ID
+func UserIDs(user *model.User) ([]int64, error) {
+	var userIDs []int64
+	err := database.DB.Model(&model.UserMap{}).Where("uuid = ?", user.UUID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// BroadcastUserIDs 所有未被禁止的用户的 Telegram 用户 ID
+func BroadcastUserIDs() ([]int64, error) {
+	var userIDs []int64
+	err := database.DB.Model(&model.UserMap{}).
+		Joins("JOIN users ON users.uuid = user_maps.uuid").
+		Where("users.banned = ?", false).
+		Order("user_maps.user_id ASC").
+		Pluck("user_maps.user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// BotStats 运行统计
+type BotStats struct {
+	Users          int64
+	PremiumUsers   int64
+	BannedUsers    int64
+	Torrents       int64
+	CachedFiles    int64
+	ActiveJobs     int64
+	DownloadsToday int64
+	Downloads      int64
+}
+
+// Stats 查询用户、种子和下载的统计
+func Stats() (*BotStats, error) {
+	stats := &BotStats{}
+	today := time.Now().Truncate(24 * time.Hour).Unix()
+	queries := []struct {
+		count *int64
+		model any
+		where string
+		args  []any
+	}{
+		{&stats.Users, &model.User{}, "", nil},
+		{&stats.PremiumUsers, &model.Permissions{}, "type = ?", []any{model.PermissionsTypePremium}},
+		{&stats.BannedUsers, &model.User{}, "banned = ?", []any{true}},
+		{&stats.Torrents, &model.TorrentInfo{}, "", nil},
+		{&stats.CachedFiles, &model.DownloadFileComment{}, "", nil},
+		{&stats.ActiveJobs, &model.DownloadJob{}, "state IN ?", []any{model.UnfinishedDownloadJobStates}},
+		{&stats.DownloadsToday, &model.DownloadHistory{}, "started_at >= ?", []any{today}},
+		{&stats.Downloads, &model.DownloadHistory{}, "", nil},
+	}
+	for _, query := range queries {
+		tx := database.DB.Model(query.model)
+		if query.where != "" {
+			tx = tx.Where(query.where, query.args...)
+		}
+		if err := tx.Count(query.count).Error; err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// WriteAuditLog 记录管理员操作和操作结果
+func WriteAuditLog(adminID int64, action string, target string, detail string, actionErr error) error {
+	auditLog := model.AuditLog{
+		AdminID: adminID,
+		Action:  action,
+		Target:  target,
+		Detail:  detail,
+	}
+	if actionErr != nil {
+		auditLog.Error = actionErr.Error()
+	}
+	return database.DB.Create(&auditLog).Error
+}
+
+// applyPermissionsLevel 按权限等级设置类型和各项额度
+func applyPermissionsLevel(permissions *model.Permissions, level model.Permissions) {
+	permissions.Type = level.Type
+	permissions.AsyncDownloadQuantity = level.AsyncDownloadQuantity
+	permissions.DailyDownloadQuantity = level.DailyDownloadQuantity
+	permissions.FileDownloadSize = level.FileDownloadSize
+}
+
+// downgradePermissions 恢复为普通用户，今日剩余次数不超过普通用户的额度
+func downgradePermissions(permissions *model.Permissions) {
+	applyPermissionsLevel(permissions, model.BasicPermissions)
+	permissions.DailyDownloadRemain = min(permissions.DailyDownloadRemain, permissions.DailyDownloadQuantity)
+	permissions.PremiumExpireAt = 0
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAdminUserActions(t *testing.T) {
+	if err := database.InitDatabase(database.Config{Path: filepath.Join(t.TempDir(), "test.db")}); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := User(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindUser("43"); err == nil {
+		t.Fatal("unknown user id should not be found")
+	}
+	user, err := FindUser(strconv.Itoa(42))
+	if err != nil || user.UUID != created.UUID {
+		t.Fatalf("find by user id = %+v, %v", user, err)
+	}
+	if user, err = FindUser(created.UUID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未到期时从到期时间顺延，今日剩余下载次数不变
+	permissions, err := GrantPremium(user, 30)
+	if err != nil || permissions.Type != model.PermissionsTypePremium ||
+		permissions.DailyDownloadRemain != model.BasicPermissions.DailyDownloadRemain {
+		t.Fatalf("grant = %+v, %v", permissions, err)
+	}
+	expireAt := permissions.PremiumExpireAt
+	if permissions, _ = GrantPremium(user, 1); permissions.PremiumExpireAt != time.Unix(expireAt, 0).AddDate(0, 0, 1).Unix() {
+		t.Fatalf("extended expire at = %d, want one day after %d", permissions.PremiumExpireAt, expireAt)
+	}
+
+	// 不过期的高级用户不会被改为有到期时间
+	permissions.PremiumExpireAt = 0
+	if err := setPermissions(permissions); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GrantPremium(user, 30); err == nil {
+		t.Fatal("grant should not set an expiry on a premium user without expiry")
+	}
+	if permissions, _ = UserPermissions(user); permissions.PremiumExpireAt != 0 {
+		t.Fatalf("permanent premium expire at = %d", permissions.PremiumExpireAt)
+	}
+
+	// 到期后读取权限时恢复为普通用户
+	permissions.PremiumExpireAt = time.Now().Unix() - 1
+	if err := setPermissions(permissions); err != nil {
+		t.Fatal(err)
+	}
+	if permissions, err = UserPermissions(user); err != nil || permissions.Type != model.PermissionsTypeBasic ||
+		permissions.DailyDownloadRemain > model.BasicPermissions.DailyDownloadQuantity {
+		t.Fatalf("expired permissions = %+v, %v", permissions, err)
+	}
+
+	if err := SetUserBanned(user, true, "spam"); err != nil || !IsBanned(42) {
+		t.Fatalf("ban = %v, banned = %v", err, IsBanned(42))
+	}
+	if userIDs, err := BroadcastUserIDs(); err != nil || len(userIDs) != 0 {
+		t.Fatalf("broadcast user ids = %v, %v", userIDs, err)
+	}
+	if err := SetUserBanned(user, false, "spam"); err != nil || IsBanned(42) || user.BanReason != "" {
+		t.Fatalf("unban = %v, user = %+v", err, user)
+	}
+}
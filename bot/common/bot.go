@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"errors"
 	"log"
 	"time"
@@ -14,24 +15,73 @@ const maxRetryOnRateLimit = 3 // 429 时最多重试次数
 
 // SendWithRetry 发送请求，遇到 429 Too Many Requests 时按 retry_after 等待后重试
 func SendWithRetry(bot *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	err := retryOnRateLimit(func() error {
+		var err error
+		msg, err = bot.Send(c)
+		return err
+	})
+	return msg, err
+}
+
+// SendMediaGroupWithRetry 发送相册，遇到 429 时等待后重试
+func SendMediaGroupWithRetry(bot *tgbotapi.BotAPI, config tgbotapi.MediaGroupConfig) ([]tgbotapi.Message, error) {
+	var messages []tgbotapi.Message
+	err := retryOnRateLimit(func() error {
+		var err error
+		messages, err = bot.SendMediaGroup(config)
+		return err
+	})
+	return messages, err
+}
+
+// copyMessages 每次最多复制的消息数量
+const maxCopyMessages = 100
+
+// CopyMessages 按顺序批量复制消息，相册会保持分组
+func CopyMessages(bot *tgbotapi.BotAPI, chatID int64, fromChatID int64, messageIDs []int) error {
+	for start := 0; start < len(messageIDs); start += maxCopyMessages {
+		ids, err := json.Marshal(messageIDs[start:min(start+maxCopyMessages, len(messageIDs))])
+		if err != nil {
+			return err
+		}
+		params := tgbotapi.Params{}
+		params.AddNonZero64("chat_id", chatID)
+		params.AddNonZero64("from_chat_id", fromChatID)
+		params["message_ids"] = string(ids)
+
+		err = retryOnRateLimit(func() error {
+			_, err := bot.MakeRequest("copyMessages", params)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryOnRateLimit 执行请求，遇到 429 Too Many Requests 时按 retry_after 等待后重试
+func retryOnRateLimit(request func() error) error {
 	var lastErr error
 	for attempt := 0; attempt <= maxRetryOnRateLimit; attempt++ {
-		msg, err := bot.Send(c)
+		err := request()
 		if err == nil {
-			return msg, nil
+			return nil
 		}
 		lastErr = err
 
-		var apiErr tgbotapi.Error
+		// tgbotapi 返回的是 *tgbotapi.Error
+		var apiErr *tgbotapi.Error
 		if !errors.As(err, &apiErr) || apiErr.Code != 429 || apiErr.RetryAfter <= 0 {
-			return msg, err
+			return err
 		}
 
 		wait := time.Duration(apiErr.RetryAfter) * time.Second
 		log.Printf("Telegram API 429 Too Many Requests, retry after %ds (attempt %d/%d)", apiErr.RetryAfter, attempt+1, maxRetryOnRateLimit+1)
 		time.Sleep(wait)
 	}
-	return tgbotapi.Message{}, lastErr
+	return lastErr
 }
 
 // SendErrorMessage 发送错误消息
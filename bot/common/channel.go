@@ -0,0 +1,62 @@
+package common
+
+import (
+	"errors"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 频道用户名（不带 @），启动时由配置文件设置，默认值见 utils 中的默认配置
+var (
+	downloadChannel string
+	helpChannel     string
+)
+
+// SetChannels 设置下载文件频道和帮助反馈频道
+func SetChannels(download string, help string) {
+	downloadChannel = download
+	helpChannel = help
+
+	discussionChatMutex.Lock()
+	discussionChatID = 0
+	discussionChatMutex.Unlock()
+}
+
+// DownloadChannel 下载文件频道，用于消息展示，如 @channel
+func DownloadChannel() string {
+	return "@" + downloadChannel
+}
+
+// HelpChannel 帮助反馈频道，用于消息展示，如 @channel
+func HelpChannel() string {
+	return "@" + helpChannel
+}
+
+var (
+	discussionChatMutex sync.Mutex
+	// 下载文件频道关联的讨论组 chat ID，首次使用时查询
+	discussionChatID int64
+)
+
+// DiscussionChatID 查询下载文件频道关联的讨论组（评论区），Bot 需要是讨论组成员才能发送和复制消息
+func DiscussionChatID(bot *tgbotapi.BotAPI) (int64, error) {
+	discussionChatMutex.Lock()
+	defer discussionChatMutex.Unlock()
+
+	if discussionChatID != 0 {
+		return discussionChatID, nil
+	}
+
+	chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{
+		ChatConfig: tgbotapi.ChatConfig{SuperGroupUsername: DownloadChannel()},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if chat.LinkedChatID == 0 {
+		return 0, errors.New("download channel has no discussion group")
+	}
+	discussionChatID = chat.LinkedChatID
+	return discussionChatID, nil
+}
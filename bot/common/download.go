@@ -4,6 +4,8 @@ import (
 	"bt-bot/database"
 	"bt-bot/database/model"
 	"errors"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -28,9 +30,10 @@ func RecordDownloadMessage(infoHash string, messageID int64) error {
 	return database.DB.Create(&downloadMessage).Error
 }
 
+// CheckDownloadComment 判断文件是否已缓存，与 GetDownloadCommentMessages 一致，没有消息 ID 的旧记录视为未缓存
 func CheckDownloadComment(infoHash string, index int) (bool, error) {
 	var downloadComment model.DownloadFileComment
-	err := database.DB.Where("info_hash = ? AND file_index = ?", infoHash, index).First(&downloadComment).Error
+	err := database.DB.Where("info_hash = ? AND file_index = ? AND message_ids <> ''", infoHash, index).First(&downloadComment).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return false, err
 	} else if err != nil {
@@ -39,10 +42,38 @@ func CheckDownloadComment(infoHash string, index int) (bool, error) {
 	return true, nil
 }
 
-func RecordDownloadComment(infoHash string, index int) error {
+func RecordDownloadComment(infoHash string, index int, chatID int64, messageIDs []int) error {
+	ids := make([]string, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		ids = append(ids, strconv.Itoa(messageID))
+	}
 	downloadComment := model.DownloadFileComment{
-		InfoHash:  infoHash,
-		FileIndex: index,
+		InfoHash:   infoHash,
+		FileIndex:  index,
+		ChatID:     chatID,
+		MessageIDs: strings.Join(ids, ","),
 	}
 	return database.DB.Create(&downloadComment).Error
 }
+
+// GetDownloadCommentMessages 查询已缓存文件的评论消息，返回讨论组 chat ID 和消息 ID 列表
+// 旧记录没有保存消息 ID 时返回 false
+func GetDownloadCommentMessages(infoHash string, index int) (int64, []int, bool, error) {
+	var downloadComment model.DownloadFileComment
+	err := database.DB.Where("info_hash = ? AND file_index = ? AND message_ids <> ''", infoHash, index).First(&downloadComment).Error
+	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil, false, nil
+	} else if err != nil {
+		return 0, nil, false, err
+	}
+
+	messageIDs := make([]int, 0)
+	for _, id := range strings.Split(downloadComment.MessageIDs, ",") {
+		messageID, err := strconv.Atoi(id)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+	return downloadComment.ChatID, messageIDs, true, nil
+}
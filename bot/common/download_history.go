@@ -0,0 +1,82 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateDownloadHistory 记录用户的一次下载，jobID 为 0 表示从缓存直接转发，记录时即已结束
+func CreateDownloadHistory(userUUID string, infoHash string, fileIndex int, target *DownloadTarget, jobID uint) (*model.DownloadHistory, error) {
+	now := time.Now().Unix()
+	history := model.DownloadHistory{
+		UserUUID:  userUUID,
+		InfoHash:  infoHash,
+		FileIndex: fileIndex,
+		FileName:  target.Name,
+		Size:      target.Length,
+		JobID:     jobID,
+		Outcome:   model.DownloadHistoryOutcomePending,
+		StartedAt: now,
+	}
+	if jobID == 0 {
+		history.Outcome = model.DownloadHistoryOutcomeCached
+		history.FinishedAt = now
+	}
+	if err := database.DB.Create(&history).Error; err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// finishDownloadHistory 下载任务结束时更新对应的下载记录
+func finishDownloadHistory(jobID uint, outcome string, errMessage string) error {
+	now := time.Now().Unix()
+	return database.DB.Model(&model.DownloadHistory{}).
+		Where("job_id = ? AND outcome = ?", jobID, model.DownloadHistoryOutcomePending).
+		Updates(map[string]any{
+			"outcome":     outcome,
+			"error":       errMessage,
+			"finished_at": now,
+			"duration":    gorm.Expr("? - started_at", now),
+		}).Error
+}
+
+// DownloadHistoryPage 分页查询用户的下载记录，最新的在前
+func DownloadHistoryPage(userUUID string, page int, pageSize int) ([]model.DownloadHistory, int64, error) {
+	var total int64
+	query := database.DB.Model(&model.DownloadHistory{}).Where("user_uuid = ?", userUUID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var histories []model.DownloadHistory
+	err := query.Order("id DESC").Offset(page * pageSize).Limit(pageSize).Find(&histories).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return histories, total, nil
+}
+
+// FindDownloadHistory 查询用户的一条下载记录
+func FindDownloadHistory(id uint, userUUID string) (*model.DownloadHistory, error) {
+	var history model.DownloadHistory
+	if err := database.DB.Where("id = ? AND user_uuid = ?", id, userUUID).First(&history).Error; err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// 结束状态的下载任务需要更新下载记录
+var finishedDownloadJobStates = []string{
+	model.DownloadJobStateDone,
+	model.DownloadJobStateFailed,
+	model.DownloadJobStateCanceled,
+}
+
+func isFinishedDownloadJobState(state string) bool {
+	return slices.Contains(finishedDownloadJobStates, state)
+}
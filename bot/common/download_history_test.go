@@ -0,0 +1,50 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadHistory(t *testing.T) {
+	if err := database.InitDatabase(database.Config{Path: filepath.Join(t.TempDir(), "test.db")}); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &DownloadTarget{FileIndexes: []int{0}, Name: "movie.mkv", Length: 42}
+	if _, err := CreateDownloadHistory("user", "hash", 0, target, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := CreateDownloadJob(1, 1, 1, "hash", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateDownloadHistory("user", "hash", 0, target, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateDownloadHistory("other", "hash", 0, target, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 下载中的状态不更新记录，结束时记录结果
+	SetDownloadJobState(job, model.DownloadJobStateDownloading, "")
+	histories, total, err := DownloadHistoryPage("user", 0, 10)
+	if err != nil || total != 2 || histories[0].Outcome != model.DownloadHistoryOutcomePending {
+		t.Fatalf("histories = %+v, total = %d, err = %v", histories, total, err)
+	}
+	SetDownloadJobState(job, model.DownloadJobStateFailed, "Timeout")
+
+	histories, _, _ = DownloadHistoryPage("user", 0, 10)
+	if histories[0].Outcome != model.DownloadHistoryOutcomeFailed || histories[0].Error != "Timeout" || histories[0].FinishedAt == 0 {
+		t.Fatalf("finished history = %+v", histories[0])
+	}
+	if histories[1].Outcome != model.DownloadHistoryOutcomeCached {
+		t.Fatalf("cached history = %+v", histories[1])
+	}
+
+	if _, err := FindDownloadHistory(histories[0].ID, "other"); err == nil {
+		t.Fatal("other user should not find the history")
+	}
+}
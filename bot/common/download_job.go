@@ -0,0 +1,62 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+
+	"gorm.io/gorm"
+)
+
+// MaxDownloadJobAttempts 任务最多恢复的次数，超过后视为失败，避免导致崩溃的任务在每次重启时反复执行
+const MaxDownloadJobAttempts = 3
+
+// CreateDownloadJob 创建下载任务，初始状态为排队中
+func CreateDownloadJob(userID int64, chatID int64, messageID int, infoHash string, fileIndex int) (*model.DownloadJob, error) {
+	job := model.DownloadJob{
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: messageID,
+		InfoHash:  infoHash,
+		FileIndex: fileIndex,
+		State:     model.DownloadJobStateQueued,
+	}
+	if err := database.DB.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SetDownloadJobState 更新下载任务状态，errMessage 仅在失败时记录
+// 任务结束时同时更新下载记录的结果和耗时
+func SetDownloadJobState(job *model.DownloadJob, state string, errMessage string) error {
+	job.State = state
+	job.Error = errMessage
+	err := database.DB.Model(job).Updates(map[string]any{
+		"state": state,
+		"error": errMessage,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if isFinishedDownloadJobState(state) {
+		return finishDownloadHistory(job.ID, state, errMessage)
+	}
+	return nil
+}
+
+// UnfinishedDownloadJobs 查询未完成的下载任务（排队中、下载中、上传中）
+func UnfinishedDownloadJobs() ([]model.DownloadJob, error) {
+	var jobs []model.DownloadJob
+	err := database.DB.Where("state IN ?", model.UnfinishedDownloadJobStates).Order("id ASC").Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// IncrementDownloadJobAttempts 记录一次任务恢复
+func IncrementDownloadJobAttempts(job *model.DownloadJob) error {
+	job.Attempts++
+	return database.DB.Model(job).UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
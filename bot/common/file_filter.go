@@ -0,0 +1,114 @@
+package common
+
+import (
+	"bt-bot/database/model"
+	"bt-bot/torrent"
+	"bt-bot/utils"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+)
+
+// FileFilter 按名称、分类和大小筛选种子中的文件
+// 名称和分类条件满足任意一个即可，大小条件必须全部满足
+type FileFilter struct {
+	Patterns   []string // 小写的通配符，包含 / 时匹配完整路径，否则匹配文件名
+	Categories []string
+	MinSize    int64
+	MaxSize    int64
+}
+
+// ParseFileFilter 解析筛选条件，如 *.srt type:video size>100MB
+// 不含通配符的词按包含匹配，即 S01 等同于 *S01*
+func ParseFileFilter(conditions []string) (*FileFilter, error) {
+	filter := &FileFilter{MaxSize: math.MaxInt64}
+	for _, condition := range conditions {
+		lower := strings.ToLower(condition)
+		switch {
+		case strings.HasPrefix(lower, "size"):
+			if err := filter.parseSize(lower[len("size"):]); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(lower, "type:"):
+			category := strings.TrimPrefix(lower, "type:")
+			if !torrent.IsFileCategory(category) {
+				return nil, fmt.Errorf("unknown type: %s", category)
+			}
+			filter.Categories = append(filter.Categories, category)
+		default:
+			if !strings.ContainsAny(lower, "*?[") {
+				lower = "*" + lower + "*"
+			}
+			if _, err := path.Match(lower, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern: %s", condition)
+			}
+			filter.Patterns = append(filter.Patterns, lower)
+		}
+	}
+	return filter, nil
+}
+
+// parseSize 解析 >100MB、>=1G、<500M、<=2GB
+func (f *FileFilter) parseSize(expr string) error {
+	operator := strings.TrimRight(expr[:min(2, len(expr))], "0123456789.")
+	size, err := utils.ParseSizeString(expr[len(operator):])
+	if err != nil {
+		return err
+	}
+	switch operator {
+	case ">":
+		f.MinSize = max(f.MinSize, size+1)
+	case ">=":
+		f.MinSize = max(f.MinSize, size)
+	case "<":
+		f.MaxSize = min(f.MaxSize, size-1)
+	case "<=":
+		f.MaxSize = min(f.MaxSize, size)
+	default:
+		return fmt.Errorf("invalid size condition: size%s", expr)
+	}
+	return nil
+}
+
+// Match 判断文件是否符合筛选条件，名称和扩展名不区分大小写
+func (f *FileFilter) Match(file model.TorrentFile) bool {
+	if file.Length < f.MinSize || file.Length > f.MaxSize {
+		return false
+	}
+	if len(f.Patterns) == 0 && len(f.Categories) == 0 {
+		return true
+	}
+
+	filePath := strings.ToLower(file.DisplayPath())
+	for _, pattern := range f.Patterns {
+		name := path.Base(filePath)
+		if strings.Contains(pattern, "/") {
+			name = filePath
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	for _, category := range f.Categories {
+		if torrent.HasFileCategory(filePath, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterFiles 种子中符合筛选条件的文件，按路径排序
+func FilterFiles(torrentInfo *model.Torrent, filter *FileFilter) []model.TorrentFile {
+	var files []model.TorrentFile
+	for _, file := range TorrentFiles(torrentInfo) {
+		if filter.Match(file) {
+			files = append(files, file)
+		}
+	}
+	slices.SortFunc(files, func(a, b model.TorrentFile) int {
+		return strings.Compare(a.DisplayPath(), b.DisplayPath())
+	})
+	return files
+}
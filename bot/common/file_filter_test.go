@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bt-bot/database/model"
+	"slices"
+	"testing"
+)
+
+func TestFileFilter(t *testing.T) {
+	torrentInfo := &model.Torrent{
+		TorrentInfo: model.TorrentInfo{InfoHash: "hash", Name: "show", IsDir: true},
+		Files: []model.TorrentFile{
+			{FileIndex: 0, Path: "Show.S01E01.MKV", Length: 200 << 20},
+			{FileIndex: 1, Path: "Show.S01E01.srt", Length: 50 << 10},
+			{FileIndex: 2, Path: "Show.S02E01.mkv", Length: 90 << 20},
+			{FileIndex: 3, Path: "Subs/Show.S02E01.ASS", Length: 60 << 10},
+			{FileIndex: 4, Path: "Cover.JPG", Length: 1 << 20},
+		},
+	}
+
+	tests := []struct {
+		conditions []string
+		want       []int
+	}{
+		{[]string{"*.srt"}, []int{1}},
+		{[]string{"type:video"}, []int{0, 2}},
+		{[]string{"type:subtitle", "*.jpg"}, []int{4, 1, 3}},
+		{[]string{"s02"}, []int{2, 3}},
+		{[]string{"type:video", "size>100MB"}, []int{0}},
+		{[]string{"size<=1M"}, []int{4, 1, 3}},
+		{[]string{"subs/*"}, []int{3}},
+	}
+	for _, test := range tests {
+		filter, err := ParseFileFilter(test.conditions)
+		if err != nil {
+			t.Fatalf("%v: %v", test.conditions, err)
+		}
+		var got []int
+		for _, file := range FilterFiles(torrentInfo, filter) {
+			got = append(got, file.FileIndex)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Fatalf("%v: got %v, want %v", test.conditions, got, test.want)
+		}
+	}
+
+	for _, conditions := range [][]string{{"type:unknown"}, {"size=1G"}, {"size>abc"}, {"[a"}} {
+		if _, err := ParseFileFilter(conditions); err == nil {
+			t.Fatalf("%v: expected error", conditions)
+		}
+	}
+
+	if EmojifyFilename("MOVIE.MKV") != EmojifyFilename("movie.mkv") || EmojifyFilename("readme") != "📄" {
+		t.Fatal("emoji should be case-insensitive")
+	}
+}
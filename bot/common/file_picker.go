@@ -0,0 +1,114 @@
+package common
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// 多选状态的保留时间，超过后视为放弃
+const filePickerTTL = 24 * time.Hour
+
+// FilePicks 多选模式中选中的文件序号，nil 表示没有进入多选模式
+type FilePicks map[int]bool
+
+type filePickerKey struct {
+	chatID    int64
+	messageID int
+}
+
+type filePicker struct {
+	infoHash  string
+	picks     FilePicks
+	updatedAt time.Time
+}
+
+// 每条文件浏览消息各自保存多选状态
+var (
+	filePickers      = make(map[filePickerKey]*filePicker)
+	filePickersMutex sync.Mutex
+)
+
+// StartFilePicker 消息进入多选模式，已在多选模式时保留已选文件
+func StartFilePicker(chatID int64, messageID int, infoHash string) {
+	filePickersMutex.Lock()
+	defer filePickersMutex.Unlock()
+
+	pickerOf(chatID, messageID, infoHash)
+}
+
+// StopFilePicker 消息退出多选模式并清空已选文件
+func StopFilePicker(chatID int64, messageID int) {
+	filePickersMutex.Lock()
+	defer filePickersMutex.Unlock()
+
+	delete(filePickers, filePickerKey{chatID, messageID})
+}
+
+// ToggleFilePicks 切换文件的选中状态：全部已选中时取消选中，否则全部选中
+// 不在多选模式时自动进入（如重启后点击旧消息的按钮）
+func ToggleFilePicks(chatID int64, messageID int, infoHash string, fileIndexes []int) {
+	filePickersMutex.Lock()
+	defer filePickersMutex.Unlock()
+
+	picker := pickerOf(chatID, messageID, infoHash)
+	allPicked := len(fileIndexes) > 0
+	for _, index := range fileIndexes {
+		if !picker.picks[index] {
+			allPicked = false
+			break
+		}
+	}
+	for _, index := range fileIndexes {
+		if allPicked {
+			delete(picker.picks, index)
+		} else {
+			picker.picks[index] = true
+		}
+	}
+}
+
+// FilePickerPicks 返回消息已选文件的副本，没有进入多选模式时返回 nil
+func FilePickerPicks(chatID int64, messageID int, infoHash string) FilePicks {
+	filePickersMutex.Lock()
+	defer filePickersMutex.Unlock()
+
+	picker, ok := filePickers[filePickerKey{chatID, messageID}]
+	if !ok || picker.infoHash != infoHash {
+		return nil
+	}
+	picks := make(FilePicks, len(picker.picks))
+	for index := range picker.picks {
+		picks[index] = true
+	}
+	return picks
+}
+
+// pickerOf 获取或创建消息的多选状态，调用方需持有锁
+func pickerOf(chatID int64, messageID int, infoHash string) *filePicker {
+	now := time.Now()
+	for key, picker := range filePickers {
+		if now.Sub(picker.updatedAt) > filePickerTTL {
+			delete(filePickers, key)
+		}
+	}
+
+	key := filePickerKey{chatID, messageID}
+	picker, ok := filePickers[key]
+	if !ok || picker.infoHash != infoHash {
+		picker = &filePicker{infoHash: infoHash, picks: make(FilePicks)}
+		filePickers[key] = picker
+	}
+	picker.updatedAt = now
+	return picker
+}
+
+// Indexes 已选文件序号
+func (p FilePicks) Indexes() []int {
+	indexes := make([]int, 0, len(p))
+	for index := range p {
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	return indexes
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilePicker(t *testing.T) {
+	if picks := FilePickerPicks(1, 1, "hash"); picks != nil {
+		t.Fatalf("picks before start = %v", picks)
+	}
+
+	StartFilePicker(1, 1, "hash")
+	if picks := FilePickerPicks(1, 1, "hash"); picks == nil || len(picks) != 0 {
+		t.Fatalf("picks after start = %v", picks)
+	}
+
+	ToggleFilePicks(1, 1, "hash", []int{3})
+	ToggleFilePicks(1, 1, "hash", []int{1, 2, 3})
+	if got := FilePickerPicks(1, 1, "hash").Indexes(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Fatalf("partially picked folder should be fully picked: %v", got)
+	}
+	ToggleFilePicks(1, 1, "hash", []int{1, 2})
+	if got := FilePickerPicks(1, 1, "hash").Indexes(); !slices.Equal(got, []int{3}) {
+		t.Fatalf("fully picked folder should be cleared: %v", got)
+	}
+
+	// 其他消息和种子互不影响
+	if picks := FilePickerPicks(1, 2, "hash"); picks != nil {
+		t.Fatalf("other message picks = %v", picks)
+	}
+	if picks := FilePickerPicks(1, 1, "other"); picks != nil {
+		t.Fatalf("other torrent picks = %v", picks)
+	}
+
+	StopFilePicker(1, 1)
+	if picks := FilePickerPicks(1, 1, "hash"); picks != nil {
+		t.Fatalf("picks after stop = %v", picks)
+	}
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"bt-bot/database"
+	"bt-bot/database/model"
+	"bt-bot/torrent"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DownloadTarget 文件序号对应的下载内容
+type DownloadTarget struct {
+	FileIndexes []int
+	Name        string
+	Length      int64
+}
+
+// SaveFileSelection 保存一组文件的选择，已存在相同的选择时直接返回
+func SaveFileSelection(infoHash string, name string, fileIndexes []int) (*model.FileSelection, error) {
+	indexes := slices.Clone(fileIndexes)
+	slices.Sort(indexes)
+	indexes = slices.Compact(indexes)
+
+	values := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		values = append(values, strconv.Itoa(index))
+	}
+
+	selection := model.FileSelection{
+		InfoHash:    infoHash,
+		Name:        name,
+		FileIndexes: strings.Join(values, ","),
+	}
+	err := database.DB.
+		Where("info_hash = ? AND file_indexes = ?", selection.InfoHash, selection.FileIndexes).
+		FirstOrCreate(&selection).Error
+	if err != nil {
+		return nil, err
+	}
+	return &selection, nil
+}
+
+// TorrentFiles 种子的文件列表，单文件种子没有文件列表记录，使用种子名称作为唯一的文件
+func TorrentFiles(torrentInfo *model.Torrent) []model.TorrentFile {
+	if len(torrentInfo.Files) > 0 || torrentInfo.IsDir {
+		return torrentInfo.Files
+	}
+	return []model.TorrentFile{{
+		InfoHash:  torrentInfo.InfoHash,
+		FileIndex: 0,
+		Length:    torrentInfo.Length,
+		Path:      torrentInfo.Name,
+		PathUtf8:  torrentInfo.NameUtf8,
+	}}
+}
+
+// categoryDownload 按分类下载的文件序号，-1 为全部文件
+type categoryDownload struct {
+	fileIndex int
+	category  string
+	name      string
+}
+
+// -2、-3 在分类表之前就已使用，保持不变以兼容已保存的下载任务和缓存
+var categoryDownloads = []categoryDownload{
+	{-2, torrent.FileCategoryImage, "All images"},
+	{-3, torrent.FileCategoryVideo, "All videos"},
+	{-4, torrent.FileCategoryAudio, "All audio"},
+	{-5, torrent.FileCategorySubtitle, "All subtitles"},
+	{-6, torrent.FileCategoryArchive, "All archives"},
+	{-7, torrent.FileCategoryDocument, "All documents"},
+}
+
+func categoryDownloadOf(fileIndex int) (categoryDownload, bool) {
+	for _, download := range categoryDownloads {
+		if download.fileIndex == fileIndex {
+			return download, true
+		}
+	}
+	return categoryDownload{}, false
+}
+
+// ResolveDownloadTarget 解析文件序号对应的文件，-1 为全部文件，-2 到 -7 为各分类的文件，更小的负数为保存的选择
+func ResolveDownloadTarget(torrentInfo *model.Torrent, fileIndex int) (*DownloadTarget, error) {
+	files := TorrentFiles(torrentInfo)
+
+	var match func(file model.TorrentFile) bool
+	target := &DownloadTarget{}
+	download, isCategory := categoryDownloadOf(fileIndex)
+	switch {
+	case fileIndex == -1:
+		target.Name = "All files"
+		match = func(file model.TorrentFile) bool { return true }
+	case isCategory:
+		target.Name = download.name
+		match = func(file model.TorrentFile) bool {
+			return torrent.HasFileCategory(file.DisplayPath(), download.category)
+		}
+	default:
+		if id, ok := model.SelectionID(fileIndex); ok {
+			var selection model.FileSelection
+			if err := database.DB.Where("id = ? AND info_hash = ?", id, torrentInfo.InfoHash).First(&selection).Error; err != nil {
+				return nil, err
+			}
+			indexes, err := selection.Indexes()
+			if err != nil {
+				return nil, err
+			}
+			target.Name = selection.Name
+			match = func(file model.TorrentFile) bool {
+				_, found := slices.BinarySearch(indexes, file.FileIndex)
+				return found
+			}
+		} else {
+			match = func(file model.TorrentFile) bool { return file.FileIndex == fileIndex }
+		}
+	}
+
+	for _, file := range files {
+		if !match(file) {
+			continue
+		}
+		target.FileIndexes = append(target.FileIndexes, file.FileIndex)
+		target.Length += file.Length
+		if fileIndex >= 0 {
+			target.Name = file.DisplayPath()
+		}
+	}
+	// 分类或文件选择没有匹配的文件时同样拒绝，避免下载 0 个文件
+	if len(target.FileIndexes) == 0 {
+		return nil, errors.New("no files to download")
+	}
+	slices.Sort(target.FileIndexes)
+	return target, nil
+}
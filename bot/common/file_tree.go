@@ -0,0 +1,108 @@
+package common
+
+import (
+	"bt-bot/database/model"
+	"slices"
+	"strings"
+)
+
+// FileTreeDir 种子文件树中的文件夹
+type FileTreeDir struct {
+	ID        int
+	Name      string
+	Parent    *FileTreeDir
+	Dirs      []*FileTreeDir
+	Files     []model.TorrentFile
+	Length    int64 // 文件夹中所有文件的总大小，包括子文件夹
+	FileCount int   // 文件夹中的文件数量，包括子文件夹
+}
+
+// FileTree 根据 TorrentFile.Path 构建的文件夹树
+// 文件夹 ID 按名称排序后深度优先分配，同一个种子每次构建的 ID 相同，可以放在按钮回调数据中
+type FileTree struct {
+	Root *FileTreeDir
+	dirs []*FileTreeDir
+}
+
+// BuildFileTree 构建种子的文件夹树，根文件夹 ID 为 0
+func BuildFileTree(torrentInfo *model.Torrent) *FileTree {
+	root := &FileTreeDir{}
+	for _, file := range TorrentFiles(torrentInfo) {
+		parts := strings.Split(file.DisplayPath(), "/")
+		dir := root
+		for _, name := range parts[:len(parts)-1] {
+			dir = dir.child(name)
+		}
+		dir.Files = append(dir.Files, file)
+	}
+
+	tree := &FileTree{Root: root}
+	tree.index(root)
+	return tree
+}
+
+func (d *FileTreeDir) child(name string) *FileTreeDir {
+	for _, dir := range d.Dirs {
+		if dir.Name == name {
+			return dir
+		}
+	}
+	dir := &FileTreeDir{Name: name, Parent: d}
+	d.Dirs = append(d.Dirs, dir)
+	return dir
+}
+
+// index 排序并分配 ID，同时统计文件夹大小
+func (t *FileTree) index(dir *FileTreeDir) {
+	dir.ID = len(t.dirs)
+	t.dirs = append(t.dirs, dir)
+
+	slices.SortFunc(dir.Dirs, func(a, b *FileTreeDir) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.SortFunc(dir.Files, func(a, b model.TorrentFile) int {
+		return strings.Compare(a.DisplayPath(), b.DisplayPath())
+	})
+
+	dir.Length, dir.FileCount = 0, len(dir.Files)
+	for _, file := range dir.Files {
+		dir.Length += file.Length
+	}
+	for _, child := range dir.Dirs {
+		t.index(child)
+		dir.Length += child.Length
+		dir.FileCount += child.FileCount
+	}
+}
+
+// Dir 根据 ID 查找文件夹
+func (t *FileTree) Dir(id int) (*FileTreeDir, bool) {
+	if id < 0 || id >= len(t.dirs) {
+		return nil, false
+	}
+	return t.dirs[id], true
+}
+
+// Path 文件夹相对种子根目录的路径，根文件夹为空字符串
+func (d *FileTreeDir) Path() string {
+	if d.Parent == nil {
+		return ""
+	}
+	if parent := d.Parent.Path(); parent != "" {
+		return parent + "/" + d.Name
+	}
+	return d.Name
+}
+
+// FileIndexes 文件夹中所有文件的序号，包括子文件夹
+func (d *FileTreeDir) FileIndexes() []int {
+	indexes := make([]int, 0, d.FileCount)
+	for _, file := range d.Files {
+		indexes = append(indexes, file.FileIndex)
+	}
+	for _, dir := range d.Dirs {
+		indexes = append(indexes, dir.FileIndexes()...)
+	}
+	slices.Sort(indexes)
+	return indexes
+}
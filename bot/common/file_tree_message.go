@@ -0,0 +1,227 @@
+package common
+
+import (
+	"bt-bot/bot/i18n"
+	"bt-bot/database/model"
+	"bt-bot/torrent"
+	"bt-bot/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	fileTreePageSize      = 20 // 每页显示的文件夹和文件数量
+	fileTreeButtonsPerRow = 2
+	fileTreeButtonLength  = 24 // 按钮上显示的名称长度
+	fileTreeLineLength    = 64 // 消息中显示的名称长度
+)
+
+// FileTreeView 文件浏览消息的显示状态
+type FileTreeView struct {
+	DirID     int
+	Page      int
+	Picks     FilePicks // 多选模式中已选的文件，nil 为浏览模式
+	SizeLimit int64     // 单次下载的大小上限，多选模式中显示
+}
+
+// FileTreeMessage 生成文件夹浏览消息：文件夹在前、文件在后，按页显示
+// 点击文件夹进入，点击文件下载（多选模式中切换选中），所有操作都编辑同一条消息
+func FileTreeMessage(torrentInfo *model.Torrent, view FileTreeView, language string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	tree := BuildFileTree(torrentInfo)
+	dir, ok := tree.Dir(view.DirID)
+	if !ok {
+		return "", nil, errors.New("invalid folder")
+	}
+	picking := view.Picks != nil
+
+	entries := len(dir.Dirs) + len(dir.Files)
+	pageCount := max(1, (entries+fileTreePageSize-1)/fileTreePageSize)
+	page := min(max(view.Page, 0), pageCount-1)
+	start, end := page*fileTreePageSize, min((page+1)*fileTreePageSize, entries)
+
+	lines := make([]string, 0, end-start)
+	var buttons []tgbotapi.InlineKeyboardButton
+	for i := start; i < end; i++ {
+		number := strconv.Itoa(i + 1)
+		if i < len(dir.Dirs) {
+			child := dir.Dirs[i]
+			entry := i18n.Replace(i18n.Text(i18n.MagnetFolderEntryCode, language), map[string]string{
+				i18n.MagnetMessagePlaceholderFileName:  truncateName(child.Name, fileTreeLineLength),
+				i18n.MagnetMessagePlaceholderFileCount: strconv.Itoa(child.FileCount),
+				i18n.MagnetMessagePlaceholderFileSize:  utils.FormatBytesToSizeString(child.Length),
+			})
+			lines = append(lines, fmt.Sprintf("📁 %s. %s", number, entry))
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📁 %s. %s", number, truncateName(child.Name, fileTreeButtonLength)),
+				fileTreeCallbackData(torrentInfo.InfoHash, child.ID, 0),
+			))
+			continue
+		}
+
+		file := dir.Files[i-len(dir.Dirs)]
+		name := fileBaseName(file.DisplayPath())
+		emoji := EmojifyFilename(name)
+		data := fmt.Sprintf("file_%s_%d", torrentInfo.InfoHash, file.FileIndex)
+		if picking {
+			emoji = "⬜"
+			if view.Picks[file.FileIndex] {
+				emoji = "✅"
+			}
+			data = fmt.Sprintf("pick_%s_%d_%d_%d", torrentInfo.InfoHash, file.FileIndex, dir.ID, page)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s. %s (%s)", emoji, number, truncateName(name, fileTreeLineLength), utils.FormatBytesToSizeString(file.Length)))
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s. %s", emoji, number, truncateName(name, fileTreeButtonLength)),
+			data,
+		))
+	}
+
+	folder := torrentInfo.Name
+	if torrentInfo.NameUtf8 != "" {
+		folder = torrentInfo.NameUtf8
+	}
+	if path := dir.Path(); path != "" {
+		folder += "/" + path
+	}
+
+	text := i18n.Replace(i18n.Text(i18n.MagnetSuccessMessageCode, language), map[string]string{
+		i18n.MagnetMessagePlaceholderMagnetLink: "magnet:?xt=urn:btih:" + torrentInfo.InfoHash,
+		i18n.MagnetMessagePlaceholderFileName:   torrentInfo.Name,
+		i18n.MagnetMessagePlaceholderFileSize:   utils.FormatBytesToSizeString(torrentInfo.TotalLength()),
+		i18n.MagnetMessagePlaceholderFileCount:  strconv.Itoa(tree.Root.FileCount),
+		i18n.MagnetMessagePlaceholderFolder:     folder + "/",
+		i18n.MagnetMessagePlaceholderPage:       strconv.Itoa(page + 1),
+		i18n.MagnetMessagePlaceholderPageCount:  strconv.Itoa(pageCount),
+		i18n.MagnetMessagePlaceholderFileList:   strings.Join(lines, "\n"),
+	})
+
+	if picking {
+		text += "\n" + pickSummary(torrentInfo, view, language)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	switch {
+	case picking:
+		var row []tgbotapi.InlineKeyboardButton
+		if dir.Parent != nil {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonParentFolderCode, language), fileTreeCallbackData(torrentInfo.InfoHash, dir.Parent.ID, 0)))
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonPickFolderCode, language), fmt.Sprintf("pickdir_%s_%d_%d", torrentInfo.InfoHash, dir.ID, page)))
+		keyboard = append(keyboard, row)
+	case dir.Parent == nil:
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("All files", "file_"+torrentInfo.InfoHash+"_-1"),
+			tgbotapi.NewInlineKeyboardButtonData("All images", "file_"+torrentInfo.InfoHash+"_-2"),
+			tgbotapi.NewInlineKeyboardButtonData("All videos", "file_"+torrentInfo.InfoHash+"_-3"),
+		})
+		if row := categoryButtons(torrentInfo); len(row) > 0 {
+			keyboard = append(keyboard, row)
+		}
+	default:
+		downloadText := i18n.Replace(i18n.Text(i18n.ButtonDownloadFolderCode, language), map[string]string{
+			i18n.MagnetMessagePlaceholderFileSize: utils.FormatBytesToSizeString(dir.Length),
+		})
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonParentFolderCode, language), fileTreeCallbackData(torrentInfo.InfoHash, dir.Parent.ID, 0)),
+			tgbotapi.NewInlineKeyboardButtonData(downloadText, fmt.Sprintf("treedl_%s_%d", torrentInfo.InfoHash, dir.ID)),
+		})
+	}
+
+	for i := 0; i < len(buttons); i += fileTreeButtonsPerRow {
+		keyboard = append(keyboard, buttons[i:min(i+fileTreeButtonsPerRow, len(buttons))])
+	}
+
+	if pageCount > 1 {
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("◀️", fileTreeCallbackData(torrentInfo.InfoHash, dir.ID, (page+pageCount-1)%pageCount)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pageCount), fileTreeCallbackData(torrentInfo.InfoHash, dir.ID, page)),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", fileTreeCallbackData(torrentInfo.InfoHash, dir.ID, (page+1)%pageCount)),
+		})
+	}
+
+	if picking {
+		downloadText := i18n.Replace(i18n.Text(i18n.ButtonDownloadPickedCode, language), map[string]string{
+			i18n.MagnetMessagePlaceholderFileCount: strconv.Itoa(len(view.Picks)),
+		})
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(downloadText, fmt.Sprintf("pickdl_%s_%d_%d", torrentInfo.InfoHash, dir.ID, page)),
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonStopPickCode, language), fmt.Sprintf("pickoff_%s_%d_%d", torrentInfo.InfoHash, dir.ID, page)),
+		})
+	} else {
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonPickFilesCode, language), fmt.Sprintf("pickon_%s_%d_%d", torrentInfo.InfoHash, dir.ID, page)),
+		})
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return text, &markup, nil
+}
+
+// categoryButtons 种子中存在的其他分类（音频、字幕、压缩包、文档）的下载按钮
+func categoryButtons(torrentInfo *model.Torrent) []tgbotapi.InlineKeyboardButton {
+	found := make(map[string]bool)
+	for _, file := range TorrentFiles(torrentInfo) {
+		found[torrent.FileCategoryOf(file.DisplayPath())] = true
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, download := range categoryDownloads {
+		// 图片和视频按钮始终显示在第一行
+		if download.fileIndex >= -3 || !found[download.category] {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(download.name, fmt.Sprintf("file_%s_%d", torrentInfo.InfoHash, download.fileIndex)))
+	}
+	return row
+}
+
+// pickSummary 多选模式中已选文件的数量和总大小，超过单次下载上限时提示
+func pickSummary(torrentInfo *model.Torrent, view FileTreeView, language string) string {
+	length := int64(0)
+	for _, file := range TorrentFiles(torrentInfo) {
+		if view.Picks[file.FileIndex] {
+			length += file.Length
+		}
+	}
+
+	summary := i18n.Replace(i18n.Text(i18n.MagnetPickerSummaryCode, language), map[string]string{
+		i18n.MagnetMessagePlaceholderFileCount: strconv.Itoa(len(view.Picks)),
+		i18n.MagnetMessagePlaceholderFileSize:  utils.FormatBytesToSizeString(length),
+		i18n.MagnetMessagePlaceholderSizeLimit: utils.FormatBytesToSizeString(view.SizeLimit),
+	})
+	if length > view.SizeLimit {
+		summary += "\n" + i18n.Text(i18n.MagnetPickerOverLimitCode, language)
+	}
+	return summary
+}
+
+// fileTreeCallbackData 浏览文件夹的按钮数据：tree_<infoHash>_<文件夹 ID>_<页码>
+func fileTreeCallbackData(infoHash string, dirID int, page int) string {
+	return fmt.Sprintf("tree_%s_%d_%d", infoHash, dirID, page)
+}
+
+func fileBaseName(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// truncateName 截断过长的名称，保留扩展名便于识别文件类型
+func truncateName(name string, length int) string {
+	runes := []rune(name)
+	if len(runes) <= length {
+		return name
+	}
+
+	ext := []rune("")
+	if i := strings.LastIndex(name, "."); i > 0 && len([]rune(name[i:])) <= 6 {
+		ext = []rune(name[i:])
+	}
+	keep := max(1, length-len(ext)-1)
+	return string(runes[:keep]) + "…" + string(ext)
+}
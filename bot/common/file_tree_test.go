@@ -0,0 +1,71 @@
+package common
+
+import (
+	"bt-bot/database/model"
+	"slices"
+	"testing"
+)
+
+func TestBuildFileTree(t *testing.T) {
+	torrentInfo := &model.Torrent{
+		TorrentInfo: model.TorrentInfo{InfoHash: "hash", Name: "show", IsDir: true},
+		Files: []model.TorrentFile{
+			{FileIndex: 0, Path: "s02/e01.mkv", Length: 30},
+			{FileIndex: 1, Path: "s01/e02.mkv", Length: 20},
+			{FileIndex: 2, Path: "s01/e01.mkv", Length: 10},
+			{FileIndex: 3, Path: "cover.jpg", Length: 1},
+			{FileIndex: 4, Path: "s01/extras/a.mkv", Length: 5},
+		},
+	}
+
+	tree := BuildFileTree(torrentInfo)
+	if tree.Root.FileCount != 5 || tree.Root.Length != 66 {
+		t.Fatalf("root = %d files %d bytes", tree.Root.FileCount, tree.Root.Length)
+	}
+
+	// 深度优先：0 根，1 s01，2 s01/extras，3 s02
+	paths := []string{"", "s01", "s01/extras", "s02"}
+	for id, path := range paths {
+		dir, ok := tree.Dir(id)
+		if !ok || dir.Path() != path {
+			t.Fatalf("dir %d = %v, want %q", id, dir, path)
+		}
+	}
+	if _, ok := tree.Dir(len(paths)); ok {
+		t.Fatal("unexpected dir")
+	}
+
+	s01, _ := tree.Dir(1)
+	if s01.Length != 35 || !slices.Equal(s01.FileIndexes(), []int{1, 2, 4}) {
+		t.Fatalf("s01 = %d bytes %v", s01.Length, s01.FileIndexes())
+	}
+	if s01.Files[0].FileIndex != 2 {
+		t.Fatalf("files not sorted: %v", s01.Files)
+	}
+}
+
+func TestBuildFileTreeSingleFile(t *testing.T) {
+	tree := BuildFileTree(&model.Torrent{
+		TorrentInfo: model.TorrentInfo{InfoHash: "hash", Name: "movie.mp4", Length: 42},
+	})
+	if len(tree.Root.Dirs) != 0 || len(tree.Root.Files) != 1 || tree.Root.Length != 42 {
+		t.Fatalf("root = %+v", tree.Root)
+	}
+}
+
+func TestResolveDownloadTargetRejectsEmptyCategory(t *testing.T) {
+	torrentInfo := &model.Torrent{
+		TorrentInfo: model.TorrentInfo{InfoHash: "hash", Name: "show", IsDir: true},
+		Files: []model.TorrentFile{
+			{FileIndex: 0, Path: "e01.mkv", Length: 10},
+		},
+	}
+
+	if _, err := ResolveDownloadTarget(torrentInfo, -2); err == nil {
+		t.Fatal("expected error for category without files")
+	}
+	target, err := ResolveDownloadTarget(torrentInfo, -3)
+	if err != nil || !slices.Equal(target.FileIndexes, []int{0}) {
+		t.Fatalf("videos = %v, %v", target, err)
+	}
+}
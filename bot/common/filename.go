@@ -0,0 +1,8 @@
+package common
+
+import "bt-bot/torrent"
+
+// EmojifyFilename 根据文件后缀返回对应的 emoji，扩展名分类见 torrent.RegisterFileCategory
+func EmojifyFilename(filename string) string {
+	return torrent.FileEmoji(filename)
+}
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"bt-bot/bot/i18n"
+	"bt-bot/database/model"
+	"bt-bot/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	historyPageSize      = 10 // 每页显示的下载记录数量
+	historyButtonsPerRow = 5
+)
+
+// HistoryMessage 用户下载记录的一页，每条记录有重新获取按钮
+// 按钮数据：hist_<页码> 翻页，histdl_<记录 ID> 重新获取
+func HistoryMessage(user *model.User, page int, language string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	histories, total, err := DownloadHistoryPage(user.UUID, max(page, 0), historyPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+	if total == 0 {
+		return i18n.Text(i18n.HistoryEmptyMessageCode, language), nil, nil
+	}
+
+	pageCount := int((total + historyPageSize - 1) / historyPageSize)
+	if page >= pageCount {
+		return HistoryMessage(user, pageCount-1, language)
+	}
+	page = max(page, 0)
+
+	entries := make([]string, 0, len(histories))
+	var buttons []tgbotapi.InlineKeyboardButton
+	for i, history := range histories {
+		number := strconv.Itoa(page*historyPageSize + i + 1)
+		duration := "--:--:--"
+		if history.FinishedAt > 0 {
+			duration = utils.FormatDuration(time.Duration(history.Duration) * time.Second)
+		}
+		entries = append(entries, i18n.Replace(i18n.Text(i18n.HistoryEntryCode, language), map[string]string{
+			i18n.HistoryMessagePlaceholderNumber:    number,
+			i18n.HistoryMessagePlaceholderFileName:  EmojifyFilename(history.FileName) + " " + truncateName(history.FileName, fileTreeLineLength),
+			i18n.HistoryMessagePlaceholderOutcome:   i18n.Text(historyOutcomeCode(history.Outcome), language),
+			i18n.HistoryMessagePlaceholderFileSize:  utils.FormatBytesToSizeString(history.Size),
+			i18n.HistoryMessagePlaceholderStartedAt: time.Unix(history.StartedAt, 0).Format("2006-01-02 15:04"),
+			i18n.HistoryMessagePlaceholderDuration:  duration,
+			i18n.HistoryMessagePlaceholderInfoHash:  history.InfoHash,
+		}))
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("🔁 "+number, fmt.Sprintf("histdl_%d", history.ID)))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(buttons); i += historyButtonsPerRow {
+		keyboard = append(keyboard, buttons[i:min(i+historyButtonsPerRow, len(buttons))])
+	}
+	if pageCount > 1 {
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData("◀️", fmt.Sprintf("hist_%d", (page+pageCount-1)%pageCount)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d/%d", page+1, pageCount), fmt.Sprintf("hist_%d", page)),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", fmt.Sprintf("hist_%d", (page+1)%pageCount)),
+		})
+	}
+
+	text := i18n.Replace(i18n.Text(i18n.HistoryMessageCode, language), map[string]string{
+		i18n.HistoryMessagePlaceholderTotal:     strconv.FormatInt(total, 10),
+		i18n.HistoryMessagePlaceholderPage:      strconv.Itoa(page + 1),
+		i18n.HistoryMessagePlaceholderPageCount: strconv.Itoa(pageCount),
+		i18n.HistoryMessagePlaceholderList:      strings.Join(entries, "\n\n"),
+	})
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return text, &markup, nil
+}
+
+func historyOutcomeCode(outcome string) string {
+	switch outcome {
+	case model.DownloadHistoryOutcomeCached:
+		return i18n.HistoryOutcomeCachedCode
+	case model.DownloadHistoryOutcomeDone:
+		return i18n.HistoryOutcomeDoneCode
+	case model.DownloadHistoryOutcomeFailed:
+		return i18n.HistoryOutcomeFailedCode
+	case model.DownloadHistoryOutcomeCanceled:
+		return i18n.HistoryOutcomeCanceledCode
+	default:
+		return i18n.HistoryOutcomePendingCode
+	}
+}
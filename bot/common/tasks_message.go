@@ -0,0 +1,94 @@
+package common
+
+import (
+	"bt-bot/bot/i18n"
+	"bt-bot/torrent"
+	"bt-bot/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// 任务按钮数据中的任务类型
+const (
+	TaskTypeMagnet   = "m"
+	TaskTypeDownload = "d"
+)
+
+// TasksMessage 用户正在进行的解析和下载任务，每个任务一行取消和跳转按钮
+// 按钮数据：taskstop_m_<infoHash>、taskstop_d_<infoHash>_<文件序号>，跳转为 taskjump_ 前缀
+func TasksMessage(userId int64, language string) (string, *tgbotapi.InlineKeyboardMarkup) {
+	magnets := torrent.MagnetTasks(userId)
+	downloads := torrent.DownloadTasks(userId)
+
+	refresh := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.Text(i18n.ButtonRefreshTasksCode, language), "taskrefresh"),
+	)
+	if len(magnets)+len(downloads) == 0 {
+		markup := tgbotapi.NewInlineKeyboardMarkup(refresh)
+		return i18n.Text(i18n.TasksEmptyMessageCode, language), &markup
+	}
+
+	var entries []string
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, task := range magnets {
+		number := strconv.Itoa(len(entries) + 1)
+		entries = append(entries, i18n.Replace(i18n.Text(i18n.TasksMagnetEntryCode, language), map[string]string{
+			i18n.TasksMessagePlaceholderNumber:      number,
+			i18n.TasksMessagePlaceholderInfoHash:    task.InfoHash,
+			i18n.TasksMessagePlaceholderElapsedTime: utils.FormatDuration(time.Since(task.StartedAt)),
+		}))
+		keyboard = append(keyboard, taskButtons(number, TaskTypeMagnet+"_"+task.InfoHash, true))
+	}
+	for _, task := range downloads {
+		number := strconv.Itoa(len(entries) + 1)
+		progress := ""
+		if task.TotalBytes > 0 {
+			progress = fmt.Sprintf(" %s (%s/%s)",
+				utils.FormatPercentage(task.BytesCompleted, task.TotalBytes),
+				utils.FormatBytesToSizeString(task.BytesCompleted),
+				utils.FormatBytesToSizeString(task.TotalBytes),
+			)
+		}
+		entries = append(entries, i18n.Replace(i18n.Text(i18n.TasksDownloadEntryCode, language), map[string]string{
+			i18n.TasksMessagePlaceholderNumber:      number,
+			i18n.TasksMessagePlaceholderFileName:    task.FileName,
+			i18n.TasksMessagePlaceholderState:       i18n.Text(downloadTaskStateCode(task.State), language),
+			i18n.TasksMessagePlaceholderProgress:    progress,
+			i18n.TasksMessagePlaceholderElapsedTime: utils.FormatDuration(time.Since(task.StartedAt)),
+		}))
+		// 上传中的任务已经无法取消
+		cancelable := task.State != torrent.DownloadTaskStateUploading
+		keyboard = append(keyboard, taskButtons(number, fmt.Sprintf("%s_%s_%d", TaskTypeDownload, task.InfoHash, task.FileIndex), cancelable))
+	}
+	keyboard = append(keyboard, refresh)
+
+	text := i18n.Replace(i18n.Text(i18n.TasksMessageCode, language), map[string]string{
+		i18n.TasksMessagePlaceholderTaskCount: strconv.Itoa(len(entries)),
+		i18n.TasksMessagePlaceholderTaskList:  strings.Join(entries, "\n\n"),
+	})
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return text, &markup
+}
+
+func taskButtons(number string, task string, cancelable bool) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	if cancelable {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("🛑 "+number, "taskstop_"+task))
+	}
+	return append(row, tgbotapi.NewInlineKeyboardButtonData("↩️ "+number, "taskjump_"+task+"_"+number))
+}
+
+func downloadTaskStateCode(state string) string {
+	switch state {
+	case torrent.DownloadTaskStateDownloading:
+		return i18n.TasksStateDownloadingCode
+	case torrent.DownloadTaskStateUploading:
+		return i18n.TasksStateUploadingCode
+	default:
+		return i18n.TasksStateMetadataCode
+	}
+}
@@ -3,6 +3,7 @@ package common
 import (
 	"bt-bot/database"
 	"bt-bot/database/model"
+	"bytes"
 	"strings"
 
 	"github.com/anacrolix/torrent/metainfo"
@@ -19,7 +20,8 @@ func SaveTorrentInfo(infoHash string, info *metainfo.Info) (*model.Torrent, erro
 		IsDir:       info.IsDir(),
 	}
 
-	if err := database.DB.Save(torrentInfo).Error; err != nil {
+	// 元信息只由 SaveTorrentMetaInfo 写入，重新解析磁力链接时不能覆盖已保存的元信息
+	if err := database.DB.Omit("meta_info").Save(torrentInfo).Error; err != nil {
 		return nil, err
 	}
 
@@ -54,6 +56,17 @@ func SaveTorrentInfo(infoHash string, info *metainfo.Info) (*model.Torrent, erro
 	}, nil
 }
 
+// SaveTorrentMetaInfo 保存完整的 .torrent 元信息，下载时直接使用，无需再通过 DHT 获取元信息
+func SaveTorrentMetaInfo(infoHash string, mi *metainfo.MetaInfo) error {
+	var buf bytes.Buffer
+	if err := mi.Write(&buf); err != nil {
+		return err
+	}
+	return database.DB.Model(&model.TorrentInfo{}).
+		Where("info_hash = ?", infoHash).
+		Update("meta_info", buf.Bytes()).Error
+}
+
 func GetTorrentInfo(infoHash string) (*model.Torrent, error) {
 	var torrentInfo model.TorrentInfo
 	if err := database.DB.Where("info_hash = ?", infoHash).First(&torrentInfo).Error; err != nil {
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"bt-bot/database"
+	"path/filepath"
+	"testing"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+func TestSaveTorrentInfoKeepsMetaInfo(t *testing.T) {
+	if err := database.InitDatabase(database.Config{Path: filepath.Join(t.TempDir(), "test.db")}); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &metainfo.Info{Name: "movie.mkv", Length: 42}
+	if _, err := SaveTorrentInfo("hash", info); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveTorrentMetaInfo("hash", &metainfo.MetaInfo{InfoBytes: []byte("d4:name9:movie.mkve")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新解析磁力链接后保存的种子信息不包含元信息
+	if _, err := SaveTorrentInfo("hash", info); err != nil {
+		t.Fatal(err)
+	}
+	torrentInfo, err := GetTorrentInfo("hash")
+	if err != nil || len(torrentInfo.MetaInfo) == 0 {
+		t.Fatalf("meta info = %q, %v", torrentInfo.MetaInfo, err)
+	}
+
+	if _, err := SaveTorrentInfo("new", info); err != nil {
+		t.Fatal(err)
+	}
+	if torrentInfo, err = GetTorrentInfo("new"); err != nil || torrentInfo.Name != "movie.mkv" {
+		t.Fatalf("new torrent info = %+v, %v", torrentInfo, err)
+	}
+}
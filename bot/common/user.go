@@ -141,6 +141,15 @@ func permissions(premium string) (*model.Permissions, error) {
 		return nil, err
 	}
 
+	// 高级用户到期后恢复为普通用户
+	if permissions.Type == model.PermissionsTypePremium && permissions.PremiumExpireAt > 0 &&
+		time.Now().Unix() >= permissions.PremiumExpireAt {
+		downgradePermissions(&permissions)
+		if err := setPermissions(&permissions); err != nil {
+			return nil, err
+		}
+	}
+
 	return &permissions, nil
 }
 
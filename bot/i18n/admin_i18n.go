@@ -0,0 +1,132 @@
+package i18n
+
+const (
+	AdminUsageMessageCode        = "admin_usage_message"
+	AdminUserNotFoundMessageCode = "admin_user_not_found_message"
+	AdminDoneMessageCode         = "admin_done_message"
+	AdminUserInfoMessageCode     = "admin_user_info_message"
+	AdminStatsMessageCode        = "admin_stats_message"
+	AdminBroadcastStartCode      = "admin_broadcast_start"
+	AdminBroadcastDoneCode       = "admin_broadcast_done"
+	BannedMessageCode            = "banned_message"
+
+	AdminMessagePlaceholderTarget                = "{target}"
+	AdminMessagePlaceholderAction                = "{action}"
+	AdminMessagePlaceholderUserInfo              = "{user_info}"
+	AdminMessagePlaceholderUUID                  = "{uuid}"
+	AdminMessagePlaceholderUserIDs               = "{user_ids}"
+	AdminMessagePlaceholderLanguage              = "{language}"
+	AdminMessagePlaceholderType                  = "{type}"
+	AdminMessagePlaceholderPremiumExpireAt       = "{premium_expire_at}"
+	AdminMessagePlaceholderDailyDownloadRemain   = "{daily_download_remain}"
+	AdminMessagePlaceholderDailyDownloadQuantity = "{daily_download_quantity}"
+	AdminMessagePlaceholderAsyncDownloadQuantity = "{async_download_quantity}"
+	AdminMessagePlaceholderFileDownloadSize      = "{file_download_size}"
+	AdminMessagePlaceholderBanned                = "{banned}"
+	AdminMessagePlaceholderReason                = "{reason}"
+	AdminMessagePlaceholderUsers                 = "{users}"
+	AdminMessagePlaceholderPremiumUsers          = "{premium_users}"
+	AdminMessagePlaceholderBannedUsers           = "{banned_users}"
+	AdminMessagePlaceholderTorrents              = "{torrents}"
+	AdminMessagePlaceholderCachedFiles           = "{cached_files}"
+	AdminMessagePlaceholderActiveJobs            = "{active_jobs}"
+	AdminMessagePlaceholderDownloadsToday        = "{downloads_today}"
+	AdminMessagePlaceholderDownloads             = "{downloads}"
+	AdminMessagePlaceholderCount                 = "{count}"
+	AdminMessagePlaceholderSent                  = "{sent}"
+	AdminMessagePlaceholderFailed                = "{failed}"
+)
+
+const (
+	AdminUsageMessageZH = `
+管理命令（<目标> 为用户 ID 或唯一标识）：
+• /grant <目标> premium <天数> - 升级为高级用户，未到期时顺延
+• /revoke <目标> - 恢复为普通用户
+• /ban <目标> [原因] - 禁止使用
+• /unban <目标> - 解除禁止
+• /resetquota <目标> - 重置今日下载次数
+• /userinfo <目标> - 查看用户信息
+• /stats - 查看运行统计
+• /broadcast <内容> - 向所有未被禁止的用户发送消息
+`
+	AdminUsageMessageEN = `
+Admin commands (<target> is a user ID or unique identifier):
+• /grant <target> premium <days> - Upgrade to premium, extending an unexpired premium
+• /revoke <target> - Downgrade to basic
+• /ban <target> [reason] - Ban the user
+• /unban <target> - Unban the user
+• /resetquota <target> - Reset today's download quantity
+• /userinfo <target> - Show user information
+• /stats - Show bot statistics
+• /broadcast <text> - Send a message to all users who are not banned
+`
+)
+
+const (
+	AdminUserNotFoundMessageZH = "❌ 找不到用户：{target}"
+	AdminUserNotFoundMessageEN = "❌ User not found: {target}"
+)
+
+const (
+	AdminDoneMessageZH = "✅ /{action} 已完成\n{user_info}"
+	AdminDoneMessageEN = "✅ /{action} done\n{user_info}"
+)
+
+const (
+	AdminUserInfoMessageZH = `
+👤 用户信息
+唯一标识：{uuid}
+用户 ID：{user_ids}
+使用语言：{language}
+用户类型：{type}
+高级用户到期：{premium_expire_at}
+剩余每日下载数量：{daily_download_remain}/{daily_download_quantity}
+并发下载数量：{async_download_quantity}
+下载文件大小限制：{file_download_size}
+禁止使用：{banned} {reason}
+`
+	AdminUserInfoMessageEN = `
+👤 User information
+Unique identifier: {uuid}
+User IDs: {user_ids}
+Language: {language}
+Type: {type}
+Premium expires: {premium_expire_at}
+Remaining daily downloads: {daily_download_remain}/{daily_download_quantity}
+Concurrent download quantity: {async_download_quantity}
+Download file size limit: {file_download_size}
+Banned: {banned} {reason}
+`
+)
+
+const (
+	AdminStatsMessageZH = `
+📊 运行统计
+用户：{users}（高级 {premium_users}，禁止 {banned_users}）
+种子：{torrents}
+已缓存文件：{cached_files}
+未完成的下载任务：{active_jobs}
+下载次数：今日 {downloads_today}，累计 {downloads}
+`
+	AdminStatsMessageEN = `
+📊 Bot statistics
+Users: {users} ({premium_users} premium, {banned_users} banned)
+Torrents: {torrents}
+Cached files: {cached_files}
+Unfinished download jobs: {active_jobs}
+Downloads: {downloads_today} today, {downloads} in total
+`
+)
+
+const (
+	AdminBroadcastStartZH = "📢 开始向 {count} 个用户发送消息..."
+	AdminBroadcastStartEN = "📢 Broadcasting to {count} users..."
+
+	AdminBroadcastDoneZH = "📢 发送完成：成功 {sent}，失败 {failed}"
+	AdminBroadcastDoneEN = "📢 Broadcast finished: {sent} sent, {failed} failed"
+)
+
+const (
+	BannedMessageZH = "🚫 你已被禁止使用本机器人 {reason}"
+	BannedMessageEN = "🚫 You have been banned from using this bot {reason}"
+)
@@ -10,4 +10,44 @@ const (
 
 	ButtonStopMagnetZH = "🛑 停止解析"
 	ButtonStopMagnetEN = "🛑 Stop Parsing"
+
+	ButtonParentFolderCode = "button_parent_folder"
+
+	ButtonParentFolderZH = "⬆️ 上一级"
+	ButtonParentFolderEN = "⬆️ Up"
+
+	ButtonDownloadFolderCode = "button_download_folder"
+
+	ButtonDownloadFolderZH = "📥 下载此文件夹（{file_size}）"
+	ButtonDownloadFolderEN = "📥 Download folder ({file_size})"
+
+	ButtonPickFilesCode = "button_pick_files"
+
+	ButtonPickFilesZH = "☑️ 多选文件"
+	ButtonPickFilesEN = "☑️ Select files"
+
+	ButtonPickFolderCode = "button_pick_folder"
+
+	ButtonPickFolderZH = "☑️ 全选/取消本文件夹"
+	ButtonPickFolderEN = "☑️ Toggle this folder"
+
+	ButtonDownloadPickedCode = "button_download_picked"
+
+	ButtonDownloadPickedZH = "📥 下载所选（{file_count}）"
+	ButtonDownloadPickedEN = "📥 Download selected ({file_count})"
+
+	ButtonStopPickCode = "button_stop_pick"
+
+	ButtonStopPickZH = "✖️ 退出多选"
+	ButtonStopPickEN = "✖️ Cancel selection"
+
+	ButtonDownloadFilteredCode = "button_download_filtered"
+
+	ButtonDownloadFilteredZH = "📥 下载全部 {file_count} 个文件（{file_size}）"
+	ButtonDownloadFilteredEN = "📥 Download all {file_count} files ({file_size})"
+
+	ButtonRefreshTasksCode = "button_refresh_tasks"
+
+	ButtonRefreshTasksZH = "🔄 刷新"
+	ButtonRefreshTasksEN = "🔄 Refresh"
 )
@@ -10,6 +10,7 @@ const (
 	DownloadSendFileMessageCode   = "download_send_file_message"
 	DownloadSuccessMessageCode    = "download_success_message"
 	DownloadFailedMessageCode     = "download_failed_message"
+	DownloadStalledMessageCode    = "download_stalled_message"
 
 	DownloadMessagePlaceholderMagnet          = "{magnet}"
 	DownloadMessagePlaceholderErrorMessage    = "{error_message}"
@@ -19,11 +20,20 @@ const (
 	DownloadMessagePlaceholderTotalBytes      = "{total_bytes}"
 	DownloadMessagePlaceholderDownloadChannel = "{download_channel}"
 	DownloadMessagePlaceholderElapsedTime     = "{elapsed_time}"
+	DownloadMessagePlaceholderSlotsInUse      = "{slots_in_use}"
+	DownloadMessagePlaceholderSlotsLimit      = "{slots_limit}"
+	DownloadMessagePlaceholderSpeed           = "{speed}"
+	DownloadMessagePlaceholderETA             = "{eta}"
+	DownloadMessagePlaceholderFileNumber      = "{file_number}"
+	DownloadMessagePlaceholderFileCount       = "{file_count}"
+	DownloadMessagePlaceholderUploadFile      = "{upload_file}"
+	DownloadMessagePlaceholderPeers           = "{peers}"
+	DownloadMessagePlaceholderSeeders         = "{seeders}"
 )
 
 const (
-	DownloadAlreadyDownloadingMessageZH = "❌ 已经有一个在下载了，请稍后再试"
-	DownloadAlreadyDownloadingMessageEN = "❌ Already downloading, please try again later"
+	DownloadAlreadyDownloadingMessageZH = "❌ 并发下载数量已满（{slots_in_use}/{slots_limit}），请等待当前下载完成后再试"
+	DownloadAlreadyDownloadingMessageEN = "❌ All download slots are in use ({slots_in_use}/{slots_limit}), please try again after a download finishes"
 )
 
 const (
@@ -61,6 +71,9 @@ const (
 ⏱️ 当前耗时: {elapsed_time}
 💾 正在下载文件：
 [{percent}({bytes_completed}/{total_bytes})] {download_files}
+🚀 下载速度: {speed}
+👥 连接节点: {peers}（做种 {seeders}）
+⏳ 剩余时间: {eta}
 `
 
 	DownloadProcessingMessageEN = `
@@ -72,6 +85,9 @@ const (
 ⏱️ Elapsed time: {elapsed_time}
 💾 Downloading:
 [{percent}({bytes_completed}/{total_bytes})] {download_files}
+🚀 Download speed: {speed}
+👥 Peers: {peers} ({seeders} seeding)
+⏳ Remaining time: {eta}
 `
 )
 
@@ -82,6 +98,11 @@ const (
 🔗 Magent: {magnet}
 💾 正在发送文件：
 {download_files}
+
+📤 上传进度（{file_number}/{file_count}）：
+[{percent}({bytes_completed}/{total_bytes})] {upload_file}
+🚀 上传速度：{speed}
+⏳ 剩余时间：{eta}
 `
 
 	DownloadSendFileMessageEN = `
@@ -90,6 +111,11 @@ const (
 🔗 Magnet: {magnet}
 💾 Sending file:
 {download_files}
+
+📤 Upload progress ({file_number}/{file_count}):
+[{percent}({bytes_completed}/{total_bytes})] {upload_file}
+🚀 Upload speed: {speed}
+⏳ Remaining time: {eta}
 `
 )
 
@@ -137,3 +163,30 @@ const (
 {download_files}
 `
 )
+
+// 下载停滞：长时间没有节点或没有下载进度
+const (
+	DownloadStalledMessageZH = `
+❌ 下载停滞
+
+⚠️ 长时间没有可用节点或没有任何下载进度，资源可能已无人做种，下载已自动结束。
+本次下载不计入每日下载数量，可稍后重试或更换资源。
+
+🔗 磁力链接: {magnet}
+⏱️ 当前耗时: {elapsed_time}
+💾 下载文件：
+{download_files}
+`
+
+	DownloadStalledMessageEN = `
+❌ Download stalled
+
+⚠️ No peers or no progress for a long time, the resource may have no seeds. The download has been stopped.
+This download does not count towards your daily quota, please try again later or use another resource.
+
+🔗 Magnet: {magnet}
+⏱️ Elapsed time: {elapsed_time}
+💾 Download file:
+{download_files}
+`
+)
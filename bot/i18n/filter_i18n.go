@@ -0,0 +1,67 @@
+package i18n
+
+const (
+	FilterUsageMessageCode           = "filter_usage_message"
+	FilterMessagePlaceholderError    = "{error_message}"
+	FilterMessagePlaceholderCategory = "{categories}"
+
+	FilterTorrentNotFoundMessageCode = "filter_torrent_not_found_message"
+	FilterNoMatchMessageCode         = "filter_no_match_message"
+
+	FilterResultMessageCode           = "filter_result_message"
+	FilterMessagePlaceholderFileCount = "{file_count}"
+	FilterMessagePlaceholderFileSize  = "{file_size}"
+	FilterMessagePlaceholderFileList  = "{file_list}"
+)
+
+const (
+	FilterUsageMessageZH = `
+🔍 筛选种子中的文件：
+/filter <infohash> <条件...>
+
+条件：
+• *.srt、S01 - 文件名，不区分大小写，不含通配符时按包含匹配
+• type:<分类> - 可用分类：{categories}
+• size>100MB、size<=2GB - 文件大小
+
+名称和分类满足任意一个即可，大小条件必须全部满足。
+infohash 是磁力链接中 btih: 后面的 40 位字符。
+{error_message}
+`
+	FilterUsageMessageEN = `
+🔍 Filter a torrent's files:
+/filter <infohash> <conditions...>
+
+Conditions:
+• *.srt, S01 - file name, case-insensitive, plain words match anywhere in the name
+• type:<category> - available categories: {categories}
+• size>100MB, size<=2GB - file size
+
+A file needs to match any name or type condition and every size condition.
+The infohash is the 40 characters after btih: in the magnet link.
+{error_message}
+`
+)
+
+const (
+	FilterTorrentNotFoundMessageZH = "❌ 没有找到该种子，请先发送磁力链接或种子文件进行解析"
+	FilterTorrentNotFoundMessageEN = "❌ Torrent not found, send its magnet link or .torrent file first"
+)
+
+const (
+	FilterNoMatchMessageZH = "🔍 没有符合条件的文件"
+	FilterNoMatchMessageEN = "🔍 No files match the filter"
+)
+
+const (
+	FilterResultMessageZH = `
+🔍 符合条件的文件：{file_count} 个，共 {file_size}
+
+{file_list}
+`
+	FilterResultMessageEN = `
+🔍 Matching files: {file_count}, {file_size} in total
+
+{file_list}
+`
+)
@@ -9,12 +9,15 @@ const (
 
 const (
 	HelpMessageZH = `
-💡 提示：直接发送磁力链接也可以自动解析
+💡 提示：直接发送磁力链接或 .torrent 种子文件也可以自动解析
 
 可用命令：
 • /start - 开始使用 bot
 • /magnet <磁力链接> - 解析磁力链接信息
+• /filter <infohash> <条件> - 按名称、分类或大小筛选文件
 • /self - 个人消息
+• /tasks - 查看和取消正在进行的任务
+• /history - 下载记录，可重新获取文件
 • /help - 显示帮助信息
 • /recommend - 推荐群组频道
 
@@ -24,12 +27,15 @@ Bot频道：
 `
 
 	HelpMessageEN = `
-💡 Tip: Directly sending a magnet link can also automatically parse
+💡 Tip: Directly sending a magnet link or a .torrent file can also automatically parse
 
 Available commands:
 • /start - Start using bot
 • /magnet <magnet link> - Parse magnet link information
+• /filter <infohash> <conditions> - Filter files by name, type or size
 • /self - Personal message
+• /tasks - View and cancel running tasks
+• /history - Download history, get files again
 • /help - Display help information
 
 Bot channel:
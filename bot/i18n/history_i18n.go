@@ -0,0 +1,69 @@
+package i18n
+
+const (
+	HistoryMessageCode                 = "history_message"
+	HistoryMessagePlaceholderTotal     = "{total}"
+	HistoryMessagePlaceholderPage      = "{page}"
+	HistoryMessagePlaceholderPageCount = "{page_count}"
+	HistoryMessagePlaceholderList      = "{history_list}"
+	HistoryMessagePlaceholderNumber    = "{number}"
+	HistoryMessagePlaceholderFileName  = "{file_name}"
+	HistoryMessagePlaceholderFileSize  = "{file_size}"
+	HistoryMessagePlaceholderOutcome   = "{outcome}"
+	HistoryMessagePlaceholderStartedAt = "{started_at}"
+	HistoryMessagePlaceholderDuration  = "{duration}"
+	HistoryMessagePlaceholderInfoHash  = "{info_hash}"
+
+	HistoryEmptyMessageCode = "history_empty_message"
+	HistoryEntryCode        = "history_entry"
+
+	HistoryOutcomePendingCode  = "history_outcome_pending"
+	HistoryOutcomeCachedCode   = "history_outcome_cached"
+	HistoryOutcomeDoneCode     = "history_outcome_done"
+	HistoryOutcomeFailedCode   = "history_outcome_failed"
+	HistoryOutcomeCanceledCode = "history_outcome_canceled"
+)
+
+const (
+	HistoryMessageZH = `
+🕘 下载记录（共 {total} 条，第 {page}/{page_count} 页）：
+
+{history_list}
+
+点击 🔁 重新获取文件，已缓存的文件会直接发送
+`
+	HistoryMessageEN = `
+🕘 Download history ({total} in total, page {page}/{page_count}):
+
+{history_list}
+
+Tap 🔁 to get the files again, cached files are sent right away
+`
+)
+
+const (
+	HistoryEmptyMessageZH = "🕘 还没有下载记录"
+	HistoryEmptyMessageEN = "🕘 No downloads yet"
+)
+
+const (
+	HistoryEntryZH = "{number}. {file_name}\n{outcome} · {file_size} · {started_at} · ⏱ {duration}\n🧲 {info_hash}"
+	HistoryEntryEN = "{number}. {file_name}\n{outcome} · {file_size} · {started_at} · ⏱ {duration}\n🧲 {info_hash}"
+)
+
+const (
+	HistoryOutcomePendingZH = "⏳ 进行中"
+	HistoryOutcomePendingEN = "⏳ In progress"
+
+	HistoryOutcomeCachedZH = "⚡ 缓存发送"
+	HistoryOutcomeCachedEN = "⚡ Sent from cache"
+
+	HistoryOutcomeDoneZH = "✅ 完成"
+	HistoryOutcomeDoneEN = "✅ Done"
+
+	HistoryOutcomeFailedZH = "❌ 失败"
+	HistoryOutcomeFailedEN = "❌ Failed"
+
+	HistoryOutcomeCanceledZH = "🛑 已取消"
+	HistoryOutcomeCanceledEN = "🛑 Canceled"
+)
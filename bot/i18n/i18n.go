@@ -31,6 +31,50 @@ func init() {
 		MagnetProcessingMessageCode:     MagnetProcessingMessageZH,
 		MagnetErrorMessageCode:          MagnetErrorMessageZH,
 		MagnetSuccessMessageCode:        MagnetSuccessMessageZH,
+		MagnetFolderEntryCode:           MagnetFolderEntryZH,
+		MagnetPickerSummaryCode:         MagnetPickerSummaryZH,
+		MagnetPickerOverLimitCode:       MagnetPickerOverLimitZH,
+		MagnetPickerEmptyMessageCode:    MagnetPickerEmptyMessageZH,
+		MagnetPickerSelectionNameCode:   MagnetPickerSelectionNameZH,
+
+		// Torrent File
+		TorrentFileInvalidMessageCode: TorrentFileInvalidMessageZH,
+
+		// Filter
+		FilterUsageMessageCode:           FilterUsageMessageZH,
+		FilterTorrentNotFoundMessageCode: FilterTorrentNotFoundMessageZH,
+		FilterNoMatchMessageCode:         FilterNoMatchMessageZH,
+		FilterResultMessageCode:          FilterResultMessageZH,
+
+		// Tasks
+		TasksMessageCode:          TasksMessageZH,
+		TasksEmptyMessageCode:     TasksEmptyMessageZH,
+		TasksJumpMessageCode:      TasksJumpMessageZH,
+		TasksMagnetEntryCode:      TasksMagnetEntryZH,
+		TasksDownloadEntryCode:    TasksDownloadEntryZH,
+		TasksStateMetadataCode:    TasksStateMetadataZH,
+		TasksStateDownloadingCode: TasksStateDownloadingZH,
+		TasksStateUploadingCode:   TasksStateUploadingZH,
+
+		// History
+		HistoryMessageCode:         HistoryMessageZH,
+		HistoryEmptyMessageCode:    HistoryEmptyMessageZH,
+		HistoryEntryCode:           HistoryEntryZH,
+		HistoryOutcomePendingCode:  HistoryOutcomePendingZH,
+		HistoryOutcomeCachedCode:   HistoryOutcomeCachedZH,
+		HistoryOutcomeDoneCode:     HistoryOutcomeDoneZH,
+		HistoryOutcomeFailedCode:   HistoryOutcomeFailedZH,
+		HistoryOutcomeCanceledCode: HistoryOutcomeCanceledZH,
+
+		// Admin
+		AdminUsageMessageCode:        AdminUsageMessageZH,
+		AdminUserNotFoundMessageCode: AdminUserNotFoundMessageZH,
+		AdminDoneMessageCode:         AdminDoneMessageZH,
+		AdminUserInfoMessageCode:     AdminUserInfoMessageZH,
+		AdminStatsMessageCode:        AdminStatsMessageZH,
+		AdminBroadcastStartCode:      AdminBroadcastStartZH,
+		AdminBroadcastDoneCode:       AdminBroadcastDoneZH,
+		BannedMessageCode:            BannedMessageZH,
 
 		// Download
 		DownloadAlreadyDownloadingMessageCode:          DownloadAlreadyDownloadingMessageZH,
@@ -42,10 +86,19 @@ func init() {
 		DownloadProcessingMessageCode: DownloadProcessingMessageZH,
 		DownloadSuccessMessageCode:    DownloadSuccessMessageZH,
 		DownloadFailedMessageCode:     DownloadFailedMessageZH,
+		DownloadStalledMessageCode:    DownloadStalledMessageZH,
 
 		// Button
-		ButtonStopDownloadCode: ButtonStopDownloadZH,
-		ButtonStopMagnetCode:   ButtonStopMagnetZH,
+		ButtonStopDownloadCode:     ButtonStopDownloadZH,
+		ButtonStopMagnetCode:       ButtonStopMagnetZH,
+		ButtonParentFolderCode:     ButtonParentFolderZH,
+		ButtonDownloadFolderCode:   ButtonDownloadFolderZH,
+		ButtonPickFilesCode:        ButtonPickFilesZH,
+		ButtonPickFolderCode:       ButtonPickFolderZH,
+		ButtonDownloadPickedCode:   ButtonDownloadPickedZH,
+		ButtonStopPickCode:         ButtonStopPickZH,
+		ButtonDownloadFilteredCode: ButtonDownloadFilteredZH,
+		ButtonRefreshTasksCode:     ButtonRefreshTasksZH,
 	}
 	EN_MAP = map[string]string{
 		// Error
@@ -65,6 +118,50 @@ func init() {
 		MagnetProcessingMessageCode:     MagnetProcessingMessageEN,
 		MagnetErrorMessageCode:          MagnetErrorMessageEN,
 		MagnetSuccessMessageCode:        MagnetSuccessMessageEN,
+		MagnetFolderEntryCode:           MagnetFolderEntryEN,
+		MagnetPickerSummaryCode:         MagnetPickerSummaryEN,
+		MagnetPickerOverLimitCode:       MagnetPickerOverLimitEN,
+		MagnetPickerEmptyMessageCode:    MagnetPickerEmptyMessageEN,
+		MagnetPickerSelectionNameCode:   MagnetPickerSelectionNameEN,
+
+		// Torrent File
+		TorrentFileInvalidMessageCode: TorrentFileInvalidMessageEN,
+
+		// Filter
+		FilterUsageMessageCode:           FilterUsageMessageEN,
+		FilterTorrentNotFoundMessageCode: FilterTorrentNotFoundMessageEN,
+		FilterNoMatchMessageCode:         FilterNoMatchMessageEN,
+		FilterResultMessageCode:          FilterResultMessageEN,
+
+		// Tasks
+		TasksMessageCode:          TasksMessageEN,
+		TasksEmptyMessageCode:     TasksEmptyMessageEN,
+		TasksJumpMessageCode:      TasksJumpMessageEN,
+		TasksMagnetEntryCode:      TasksMagnetEntryEN,
+		TasksDownloadEntryCode:    TasksDownloadEntryEN,
+		TasksStateMetadataCode:    TasksStateMetadataEN,
+		TasksStateDownloadingCode: TasksStateDownloadingEN,
+		TasksStateUploadingCode:   TasksStateUploadingEN,
+
+		// History
+		HistoryMessageCode:         HistoryMessageEN,
+		HistoryEmptyMessageCode:    HistoryEmptyMessageEN,
+		HistoryEntryCode:           HistoryEntryEN,
+		HistoryOutcomePendingCode:  HistoryOutcomePendingEN,
+		HistoryOutcomeCachedCode:   HistoryOutcomeCachedEN,
+		HistoryOutcomeDoneCode:     HistoryOutcomeDoneEN,
+		HistoryOutcomeFailedCode:   HistoryOutcomeFailedEN,
+		HistoryOutcomeCanceledCode: HistoryOutcomeCanceledEN,
+
+		// Admin
+		AdminUsageMessageCode:        AdminUsageMessageEN,
+		AdminUserNotFoundMessageCode: AdminUserNotFoundMessageEN,
+		AdminDoneMessageCode:         AdminDoneMessageEN,
+		AdminUserInfoMessageCode:     AdminUserInfoMessageEN,
+		AdminStatsMessageCode:        AdminStatsMessageEN,
+		AdminBroadcastStartCode:      AdminBroadcastStartEN,
+		AdminBroadcastDoneCode:       AdminBroadcastDoneEN,
+		BannedMessageCode:            BannedMessageEN,
 
 		// Download
 		DownloadAlreadyDownloadingMessageCode:          DownloadAlreadyDownloadingMessageEN,
@@ -76,10 +173,19 @@ func init() {
 		DownloadProcessingMessageCode: DownloadProcessingMessageEN,
 		DownloadSuccessMessageCode:    DownloadSuccessMessageEN,
 		DownloadFailedMessageCode:     DownloadFailedMessageEN,
+		DownloadStalledMessageCode:    DownloadStalledMessageEN,
 
 		// Button
-		ButtonStopDownloadCode: ButtonStopDownloadEN,
-		ButtonStopMagnetCode:   ButtonStopMagnetEN,
+		ButtonStopDownloadCode:     ButtonStopDownloadEN,
+		ButtonStopMagnetCode:       ButtonStopMagnetEN,
+		ButtonParentFolderCode:     ButtonParentFolderEN,
+		ButtonDownloadFolderCode:   ButtonDownloadFolderEN,
+		ButtonPickFilesCode:        ButtonPickFilesEN,
+		ButtonPickFolderCode:       ButtonPickFolderEN,
+		ButtonDownloadPickedCode:   ButtonDownloadPickedEN,
+		ButtonStopPickCode:         ButtonStopPickEN,
+		ButtonDownloadFilteredCode: ButtonDownloadFilteredEN,
+		ButtonRefreshTasksCode:     ButtonRefreshTasksEN,
 	}
 }
 
@@ -18,6 +18,17 @@ const (
 	MagnetMessagePlaceholderFileSize  = "{file_size}"
 	MagnetMessagePlaceholderFileCount = "{file_count}"
 	MagnetMessagePlaceholderFileList  = "{file_list}"
+	MagnetMessagePlaceholderFolder    = "{folder}"
+	MagnetMessagePlaceholderPage      = "{page}"
+	MagnetMessagePlaceholderPageCount = "{page_count}"
+
+	MagnetFolderEntryCode = "magnet_folder_entry"
+
+	MagnetPickerSummaryCode           = "magnet_picker_summary"
+	MagnetMessagePlaceholderSizeLimit = "{size_limit}"
+	MagnetPickerOverLimitCode         = "magnet_picker_over_limit"
+	MagnetPickerEmptyMessageCode      = "magnet_picker_empty_message"
+	MagnetPickerSelectionNameCode     = "magnet_picker_selection_name"
 )
 
 const (
@@ -91,11 +102,13 @@ const (
 📄 文件名：{file_name}
 📦 文件大小：{file_size}
 🗃️ 文件数量：{file_count}
-📋 文件列表：
+
+📂 当前文件夹：{folder}
+📋 文件列表（第 {page}/{page_count} 页）：
 
 {file_list}
 
-📥 选择文件下载：
+📥 点击文件夹进入，点击文件下载：
 `
 	MagnetSuccessMessageEN = `
 ✅ Parsing successful
@@ -104,10 +117,32 @@ const (
 📄 File name: {file_name}
 📦 File size: {file_size}
 🗃️ File count: {file_count}
-📋 File list: 
+
+📂 Current folder: {folder}
+📋 File list (page {page}/{page_count}):
 
 {file_list}
 
-📥 Select file to download:
+📥 Tap a folder to open it, tap a file to download:
 `
 )
+
+const (
+	MagnetFolderEntryZH = "{file_name}/（{file_count} 个文件，{file_size}）"
+	MagnetFolderEntryEN = "{file_name}/ ({file_count} files, {file_size})"
+)
+
+const (
+	MagnetPickerSummaryZH = "☑️ 已选 {file_count} 个文件，共 {file_size}，单次下载上限 {size_limit}"
+	MagnetPickerSummaryEN = "☑️ Selected {file_count} files, {file_size} of {size_limit} per download"
+
+	MagnetPickerOverLimitZH = "⚠️ 超过单次下载上限，请取消部分文件"
+	MagnetPickerOverLimitEN = "⚠️ Over the download size limit, deselect some files"
+
+	MagnetPickerEmptyMessageZH = "❌ 请先选择要下载的文件"
+	MagnetPickerEmptyMessageEN = "❌ Select at least one file first"
+
+	// 保存的文件选择名称，显示在下载进度和下载记录中
+	MagnetPickerSelectionNameZH = "已选的 {file_count} 个文件"
+	MagnetPickerSelectionNameEN = "Selected {file_count} files"
+)
@@ -8,6 +8,7 @@ const (
 	SelfMessagePlaceholderLanguage              = "{language}"
 	SelfMessagePlaceholderDailyDownloadRemain   = "{daily_download_remain}"
 	SelfMessagePlaceholderAsyncDownloadQuantity = "{async_download_quantity}"
+	SelfMessagePlaceholderAsyncDownloadInUse    = "{async_download_in_use}"
 	SelfMessagePlaceholderDailyDownloadQuantity = "{daily_download_quantity}"
 	SelfMessagePlaceholderFileDownloadSize      = "{file_download_size}"
 )
@@ -24,6 +25,7 @@ const (
 
 使用限制：
 - 剩余每日下载数量：{daily_download_remain}
+- 正在下载数量：{async_download_in_use}
 
 权限信息：
 - 并发下载数量：{async_download_quantity}
@@ -42,6 +44,7 @@ Using language: {language}
 
 Usage limit:
 - Remaining daily download quantity: {daily_download_remain}
+- Downloads in progress: {async_download_in_use}
 
 Permission information:
 - Concurrent download quantity: {async_download_quantity}
@@ -0,0 +1,69 @@
+package i18n
+
+const (
+	TasksMessageCode                   = "tasks_message"
+	TasksMessagePlaceholderTaskCount   = "{task_count}"
+	TasksMessagePlaceholderTaskList    = "{task_list}"
+	TasksMessagePlaceholderNumber      = "{number}"
+	TasksMessagePlaceholderInfoHash    = "{info_hash}"
+	TasksMessagePlaceholderFileName    = "{file_name}"
+	TasksMessagePlaceholderState       = "{state}"
+	TasksMessagePlaceholderProgress    = "{progress}"
+	TasksMessagePlaceholderElapsedTime = "{elapsed_time}"
+
+	TasksEmptyMessageCode = "tasks_empty_message"
+	TasksJumpMessageCode  = "tasks_jump_message"
+
+	TasksMagnetEntryCode   = "tasks_magnet_entry"
+	TasksDownloadEntryCode = "tasks_download_entry"
+
+	TasksStateMetadataCode    = "tasks_state_metadata"
+	TasksStateDownloadingCode = "tasks_state_downloading"
+	TasksStateUploadingCode   = "tasks_state_uploading"
+)
+
+const (
+	TasksMessageZH = `
+📋 正在进行的任务（{task_count}）：
+
+{task_list}
+
+点击 🛑 取消任务，点击 ↩️ 跳转到任务的进度消息
+`
+	TasksMessageEN = `
+📋 Active tasks ({task_count}):
+
+{task_list}
+
+Tap 🛑 to cancel a task, tap ↩️ to jump to its progress message
+`
+)
+
+const (
+	TasksEmptyMessageZH = "📋 当前没有正在进行的解析或下载任务"
+	TasksEmptyMessageEN = "📋 No parses or downloads are running"
+)
+
+const (
+	TasksJumpMessageZH = "⬆️ 这是任务 {number} 的进度消息"
+	TasksJumpMessageEN = "⬆️ This is the progress message of task {number}"
+)
+
+const (
+	TasksMagnetEntryZH = "🧲 {number}. 解析磁力链接 {info_hash}\n⏱ 已用时 {elapsed_time}"
+	TasksMagnetEntryEN = "🧲 {number}. Parsing magnet {info_hash}\n⏱ Elapsed {elapsed_time}"
+
+	TasksDownloadEntryZH = "📥 {number}. {file_name}\n{state}{progress} · ⏱ 已用时 {elapsed_time}"
+	TasksDownloadEntryEN = "📥 {number}. {file_name}\n{state}{progress} · ⏱ Elapsed {elapsed_time}"
+)
+
+const (
+	TasksStateMetadataZH = "🔍 获取元信息"
+	TasksStateMetadataEN = "🔍 Fetching metadata"
+
+	TasksStateDownloadingZH = "⬇️ 下载中"
+	TasksStateDownloadingEN = "⬇️ Downloading"
+
+	TasksStateUploadingZH = "⬆️ 上传中"
+	TasksStateUploadingEN = "⬆️ Uploading"
+)
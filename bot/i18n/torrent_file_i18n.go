@@ -0,0 +1,27 @@
+package i18n
+
+const (
+	TorrentFileInvalidMessageCode = "torrent_file_invalid_message"
+
+	TorrentFileMessagePlaceholderFileName     = "{file_name}"
+	TorrentFileMessagePlaceholderErrorMessage = "{error_message}"
+)
+
+const (
+	TorrentFileInvalidMessageZH = `
+❌ 种子文件解析失败
+
+⚠️ 错误信息: {error_message}
+📄 文件名：{file_name}
+
+请发送有效的 .torrent 文件或磁力链接
+`
+	TorrentFileInvalidMessageEN = `
+❌ Failed to parse torrent file
+
+⚠️ Error: {error_message}
+📄 File name: {file_name}
+
+Please send a valid .torrent file or a magnet link
+`
+)
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"bt-bot/bot/common"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AdminMiddleWare 只允许配置中的管理员执行，其他用户的命令直接忽略
+func AdminMiddleWare(next func(bot *tgbotapi.BotAPI, update *tgbotapi.Update)) func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	return func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+		if !common.IsAdmin(common.ParseUserId(update)) {
+			return
+		}
+		next(bot, update)
+	}
+}
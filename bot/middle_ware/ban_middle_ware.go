@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"bt-bot/bot/common"
+	"bt-bot/bot/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BanMiddleWare 忽略被禁止用户的消息和按钮，消息会收到一次被禁止的提示
+func BanMiddleWare(next func(bot *tgbotapi.BotAPI, update *tgbotapi.Update)) func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+	return func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
+		userId := common.ParseUserId(update)
+		if update.CallbackQuery != nil {
+			userId = common.ParseCallbackQueryUserId(update)
+		}
+		if userId == 0 || common.IsAdmin(userId) || !common.IsBanned(userId) {
+			next(bot, update)
+			return
+		}
+
+		if update.Message != nil {
+			user, err := common.User(userId)
+			if err != nil {
+				return
+			}
+			messageText := i18n.Replace(i18n.Text(i18n.BannedMessageCode, user.Language), map[string]string{
+				i18n.AdminMessagePlaceholderReason: user.BanReason,
+			})
+			common.SendWithRetry(bot, tgbotapi.NewMessage(update.Message.Chat.ID, messageText))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import "sync"
+
+// 不限制并发下载数量
+const unlimitedDownloadQuantity = -1
+
+// downloadSemaphore 单个用户的计数信号量
+type downloadSemaphore struct {
+	used int
+}
+
+// downloadLimiter 按用户 UUID 限制并发下载数量
+type downloadLimiter struct {
+	mu         sync.Mutex
+	released   *sync.Cond // 有槽位释放时通知等待中的 Acquire
+	semaphores map[string]*downloadSemaphore
+}
+
+func newDownloadLimiter() *downloadLimiter {
+	l := &downloadLimiter{
+		semaphores: make(map[string]*downloadSemaphore),
+	}
+	l.released = sync.NewCond(&l.mu)
+	return l
+}
+
+// TryAcquire 尝试占用一个下载槽位，返回占用后（或失败时当前）已使用的槽位数
+// limit 为用户权限中的并发下载数量，-1 表示不限制
+func (l *downloadLimiter) TryAcquire(uuid string, limit int) (int, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.semaphores[uuid]
+	if !ok {
+		sem = &downloadSemaphore{}
+		l.semaphores[uuid] = sem
+	}
+
+	// 权限随时可能变化（如升级高级用户），因此每次按最新的 limit 判断
+	if limit != unlimitedDownloadQuantity && sem.used >= limit {
+		return sem.used, false
+	}
+
+	sem.used++
+	return sem.used, true
+}
+
+// Acquire 占用一个下载槽位，已达上限时等待其他下载释放
+func (l *downloadLimiter) Acquire(uuid string, limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for {
+		sem, ok := l.semaphores[uuid]
+		if !ok {
+			sem = &downloadSemaphore{}
+			l.semaphores[uuid] = sem
+		}
+		if limit == unlimitedDownloadQuantity || sem.used < limit {
+			sem.used++
+			return
+		}
+		l.released.Wait()
+	}
+}
+
+// Release 释放一个下载槽位
+func (l *downloadLimiter) Release(uuid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.semaphores[uuid]
+	if !ok {
+		return
+	}
+
+	sem.used--
+	if sem.used <= 0 {
+		delete(l.semaphores, uuid)
+	}
+	l.released.Broadcast()
+}
+
+// InUse 返回用户当前已使用的下载槽位数
+func (l *downloadLimiter) InUse(uuid string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.semaphores[uuid]
+	if !ok {
+		return 0
+	}
+	return sem.used
+}
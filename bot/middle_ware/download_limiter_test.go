@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownloadLimiter(t *testing.T) {
+	limiter := newDownloadLimiter()
+
+	for i := 1; i <= 3; i++ {
+		inUse, ok := limiter.TryAcquire("premium", 3)
+		if !ok || inUse != i {
+			t.Fatalf("acquire %d: got inUse=%d ok=%v", i, inUse, ok)
+		}
+	}
+	if inUse, ok := limiter.TryAcquire("premium", 3); ok || inUse != 3 {
+		t.Fatalf("acquire over limit: got inUse=%d ok=%v", inUse, ok)
+	}
+
+	// 不同用户互不影响
+	if _, ok := limiter.TryAcquire("basic", 1); !ok {
+		t.Fatal("basic user should get a slot")
+	}
+	if _, ok := limiter.TryAcquire("basic", 1); ok {
+		t.Fatal("basic user should only get one slot")
+	}
+
+	limiter.Release("premium")
+	if limiter.InUse("premium") != 2 {
+		t.Fatalf("in use after release: %d", limiter.InUse("premium"))
+	}
+	if _, ok := limiter.TryAcquire("premium", 3); !ok {
+		t.Fatal("released slot should be reusable")
+	}
+
+	// -1 表示不限制
+	for i := 0; i < 10; i++ {
+		if _, ok := limiter.TryAcquire("unlimited", unlimitedDownloadQuantity); !ok {
+			t.Fatal("unlimited user should always get a slot")
+		}
+	}
+}
+
+func TestDownloadLimiterAcquireWaits(t *testing.T) {
+	limiter := newDownloadLimiter()
+	limiter.Acquire("basic", 1)
+
+	acquired := make(chan struct{})
+	go func() {
+		limiter.Acquire("basic", 1)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire over limit should wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.Release("basic")
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("acquire should continue after release")
+	}
+	if limiter.InUse("basic") != 1 {
+		t.Fatalf("in use after acquire: %d", limiter.InUse("basic"))
+	}
+}
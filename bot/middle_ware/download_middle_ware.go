@@ -4,16 +4,12 @@ import (
 	"bt-bot/bot/common"
 	"bt-bot/bot/i18n"
 	"errors"
-	"fmt"
-	"sync"
+	"strconv"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-var (
-	_downloadMaplock sync.Mutex
-	_downloadMap     = map[string]bool{}
-)
+var _downloadLimiter = newDownloadLimiter()
 
 func DownloadMiddleWare(next func(bot *tgbotapi.BotAPI, update *tgbotapi.Update)) func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
 	return func(bot *tgbotapi.BotAPI, update *tgbotapi.Update) {
@@ -30,35 +26,39 @@ func DownloadMiddleWare(next func(bot *tgbotapi.BotAPI, update *tgbotapi.Update)
 			return
 		}
 
-		cacheKey := fmt.Sprintf("%d", userId)
-		if isDownloadInCache(cacheKey) {
+		permissions, err := common.Permissions(userId)
+		if err != nil {
+			common.SendErrorMessage(bot, chatID, user.Language, err)
+			return
+		}
+
+		// 按用户 UUID 占用并发下载槽位
+		inUse, ok := _downloadLimiter.TryAcquire(user.UUID, permissions.AsyncDownloadQuantity)
+		if !ok {
 			messageText := i18n.Text(i18n.DownloadAlreadyDownloadingMessageCode, user.Language)
+			messageText = i18n.Replace(messageText, map[string]string{
+				i18n.DownloadMessagePlaceholderSlotsInUse: strconv.Itoa(inUse),
+				i18n.DownloadMessagePlaceholderSlotsLimit: strconv.Itoa(permissions.AsyncDownloadQuantity),
+			})
 			reply := tgbotapi.NewMessage(chatID, messageText)
 			bot.Send(reply)
 			return
 		}
-
-		addDownloadToCache(cacheKey)
-		defer removeDownloadFromCache(cacheKey)
+		defer _downloadLimiter.Release(user.UUID)
 
 		next(bot, update)
 	}
 }
 
-func addDownloadToCache(cacheKey string) {
-	_downloadMaplock.Lock()
-	defer _downloadMaplock.Unlock()
-	_downloadMap[cacheKey] = true
+// DownloadSlotsInUse 返回用户当前正在进行的下载数量
+func DownloadSlotsInUse(uuid string) int {
+	return _downloadLimiter.InUse(uuid)
 }
 
-func removeDownloadFromCache(cacheKey string) {
-	_downloadMaplock.Lock()
-	defer _downloadMaplock.Unlock()
-	delete(_downloadMap, cacheKey)
-}
-
-func isDownloadInCache(cacheKey string) bool {
-	_downloadMaplock.Lock()
-	defer _downloadMaplock.Unlock()
-	return _downloadMap[cacheKey]
+// WaitDownloadSlot 等待并占用一个下载槽位（用于恢复重启前的任务），与新的下载共用并发下载数量限制，返回释放函数
+func WaitDownloadSlot(uuid string, limit int) func() {
+	_downloadLimiter.Acquire(uuid, limit)
+	return func() {
+		_downloadLimiter.Release(uuid)
+	}
 }
@@ -2,12 +2,19 @@ package main
 
 import (
 	"bt-bot/database"
+	"bt-bot/utils"
 	"fmt"
+	"log"
 )
 
 func main() {
+	config, err := utils.LoadConfig("config.yaml")
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+
 	database.InitDatabase(database.Config{
-		Path:  "database.db",
+		Path:  config.Database.Path,
 		Debug: true,
 	})
 
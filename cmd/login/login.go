@@ -2,11 +2,24 @@ package main
 
 import (
 	"context"
+	"flag"
+	"log"
 
 	"bt-bot/telegram"
+	"bt-bot/utils"
 )
 
 func main() {
+	qr := flag.Bool("qr", false, "使用二维码登录，在已登录的设备上扫码，无需短信验证码")
+	flag.Parse()
+
+	// 加载配置文件，登录同样需要经过代理
+	config, err := utils.LoadConfig("config.yaml")
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	telegram.SetProxy(config.Bot.ProxyURL())
+
 	ctx := context.Background()
-	telegram.Login(ctx)
+	telegram.Login(ctx, *qr)
 }
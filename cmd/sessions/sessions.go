@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"bt-bot/telegram"
+	"bt-bot/utils"
+
+	"github.com/gotd/td/tg"
+)
+
+const usage = `用法: sessions <命令> [参数]
+
+命令:
+  list                      列出所有会话（不连接 Telegram）
+  check [uuid...]           连接 Telegram 检查会话是否有效，并显示所属帐号，默认检查全部
+  remove <uuid>             删除会话文件（Telegram 设备列表中的会话需要在客户端中终止）
+  rename <uuid> <新名称>    重命名会话
+  export <uuid> [文件]      导出会话文件，默认输出到标准输出`
+
+// 检查单个会话的超时时间
+const checkTimeout = 30 * time.Second
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	var err error
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "list":
+		err = list()
+	case "check":
+		err = check(args)
+	case "remove":
+		if len(args) != 1 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		err = telegram.RemoveSession(args[0])
+		if err == nil {
+			fmt.Println("已删除会话", args[0])
+		}
+	case "rename":
+		if len(args) != 2 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		err = telegram.RenameSession(args[0], args[1])
+		if err == nil {
+			fmt.Printf("已重命名会话 %s -> %s\n", args[0], args[1])
+		}
+	case "export":
+		err = export(args)
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "错误:", err)
+		os.Exit(1)
+	}
+}
+
+func list() error {
+	uuids, skipped := telegram.ScanSessions()
+	for _, uuid := range uuids {
+		data, err := telegram.LoadSessionData(uuid)
+		if err != nil {
+			return err
+		}
+		modified := ""
+		if stat, err := os.Stat(telegram.GetSessionFile(uuid)); err == nil {
+			modified = stat.ModTime().Format(time.DateTime)
+		}
+		fmt.Printf("%s\tDC%d\t%s\n", uuid, data.DC, modified)
+	}
+	for _, file := range skipped {
+		fmt.Printf("%s\t跳过: %s\n", file.Name, file.Reason)
+	}
+	fmt.Printf("共 %d 个会话\n", len(uuids))
+	return nil
+}
+
+func check(uuids []string) error {
+	// 检查需要连接 Telegram，使用配置中的代理
+	config, err := utils.LoadConfig("config.yaml")
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	telegram.SetProxy(config.Bot.ProxyURL())
+
+	if len(uuids) == 0 {
+		uuids = telegram.GetAllSessionUUIDs()
+	}
+
+	failed := 0
+	for _, uuid := range uuids {
+		ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+		user, err := telegram.CheckSession(ctx, uuid)
+		cancel()
+		if err != nil {
+			failed++
+			fmt.Printf("%s\t无效: %v\n", uuid, err)
+			continue
+		}
+		fmt.Printf("%s\t有效\t%s\n", uuid, describeUser(user))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个会话无效", failed)
+	}
+	return nil
+}
+
+func export(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	if len(args) == 1 {
+		return telegram.ExportSession(args[0], os.Stdout)
+	}
+
+	// 会话文件包含授权密钥，仅当前用户可读
+	file, err := os.OpenFile(args[1], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := telegram.ExportSession(args[0], file); err != nil {
+		os.Remove(args[1])
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "已导出会话到", args[1])
+	return nil
+}
+
+// describeUser 帐号信息：ID、用户名、手机号、是否会员
+func describeUser(user *tg.User) string {
+	parts := []string{fmt.Sprintf("id=%d", user.ID)}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		parts = append(parts, name)
+	}
+	if user.Username != "" {
+		parts = append(parts, "@"+user.Username)
+	}
+	if user.Phone != "" {
+		parts = append(parts, "+"+user.Phone)
+	}
+	if user.Premium {
+		parts = append(parts, "premium")
+	}
+	return strings.Join(parts, "\t")
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"bt-bot/database/model"
+	"fmt"
 
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -23,6 +24,10 @@ var models = []any{
 	&model.TorrentFile{},
 	&model.DownloadFileMessage{},
 	&model.DownloadFileComment{},
+	&model.DownloadJob{},
+	&model.FileSelection{},
+	&model.DownloadHistory{},
+	&model.AuditLog{},
 }
 
 func InitDatabase(config Config) error {
@@ -39,5 +44,33 @@ func InitDatabase(config Config) error {
 		return err
 	}
 
+	return migrateData(db)
+}
+
+// 数据迁移按顺序执行，已执行到的版本记录在 SQLite 的 user_version 中
+var dataMigrations = []func(tx *gorm.DB) error{
+	// 图片（-2）、视频（-3）分类的扩展名增加后，旧的评论区缓存缺少新增类型的文件，需要重新上传
+	func(tx *gorm.DB) error {
+		return tx.Where("file_index IN ?", []int{-2, -3}).Delete(&model.DownloadFileComment{}).Error
+	},
+}
+
+func migrateData(db *gorm.DB) error {
+	var version int
+	if err := db.Raw("PRAGMA user_version").Scan(&version).Error; err != nil {
+		return err
+	}
+
+	for ; version < len(dataMigrations); version++ {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := dataMigrations[version](tx); err != nil {
+				return err
+			}
+			return tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)).Error
+		})
+		if err != nil {
+			return fmt.Errorf("data migration %d: %w", version+1, err)
+		}
+	}
 	return nil
 }
@@ -32,3 +32,43 @@ func TestPermissions(t *testing.T) {
 	fmt.Println(time.Unix(1771459200, 0).Format("2006-01-02 15:04:05"))
 	fmt.Print(time.Now().Truncate(24 * time.Hour).Unix())
 }
+
+func TestMigrateData(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	if err := InitDatabase(Config{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	comments := []model.DownloadFileComment{
+		{InfoHash: "hash", FileIndex: -2, MessageIDs: "1"},
+		{InfoHash: "hash", FileIndex: -3, MessageIDs: "2"},
+		{InfoHash: "hash", FileIndex: -4, MessageIDs: "3"},
+	}
+	if err := DB.Create(&comments).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := DB.Exec("PRAGMA user_version = 0").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateData(DB); err != nil {
+		t.Fatal(err)
+	}
+	if indexes := commentFileIndexes(); fmt.Sprint(indexes) != "[-4]" {
+		t.Fatalf("file indexes = %v", indexes)
+	}
+
+	// 迁移只执行一次，之后新建的 -2 缓存保留
+	DB.Create(&model.DownloadFileComment{InfoHash: "hash", FileIndex: -2, MessageIDs: "4"})
+	if err := migrateData(DB); err != nil {
+		t.Fatal(err)
+	}
+	if indexes := commentFileIndexes(); fmt.Sprint(indexes) != "[-4 -2]" {
+		t.Fatalf("file indexes = %v", indexes)
+	}
+}
+
+func commentFileIndexes() []int {
+	var indexes []int
+	DB.Model(&model.DownloadFileComment{}).Order("file_index").Pluck("file_index", &indexes)
+	return indexes
+}
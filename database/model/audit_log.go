@@ -0,0 +1,12 @@
+package model
+
+// AuditLog 管理员操作记录
+type AuditLog struct {
+	ID        uint   `gorm:"column:id;primaryKey;autoIncrement"`
+	AdminID   int64  `gorm:"column:admin_id;index"` // 管理员的 Telegram 用户 ID
+	Action    string `gorm:"column:action;type:varchar(32)"`
+	Target    string `gorm:"column:target;type:varchar(255);index"` // 目标用户 UUID，没有目标时为空
+	Detail    string `gorm:"column:detail"`
+	Error     string `gorm:"column:error"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime"`
+}
@@ -1,6 +1,8 @@
 package model
 
 type DownloadFileComment struct {
-	InfoHash  string `gorm:"column:info_hash;"`
-	FileIndex int    `gorm:"column:file_index;"`
+	InfoHash   string `gorm:"column:info_hash;"`
+	FileIndex  int    `gorm:"column:file_index;"`
+	ChatID     int64  `gorm:"column:chat_id;"`     // 评论区讨论组的 Bot API chat ID
+	MessageIDs string `gorm:"column:message_ids;"` // 评论区文件消息 ID，逗号分隔
 }
@@ -0,0 +1,27 @@
+package model
+
+// DownloadHistory 用户的下载记录，从缓存转发和下载任务都会记录
+type DownloadHistory struct {
+	ID         uint   `gorm:"column:id;primaryKey;autoIncrement"`
+	UserUUID   string `gorm:"column:user_uuid;type:varchar(255);index"`
+	InfoHash   string `gorm:"column:info_hash;type:varchar(255)"`
+	FileIndex  int    `gorm:"column:file_index"`
+	FileName   string `gorm:"column:file_name"`
+	Size       int64  `gorm:"column:size"`
+	JobID      uint   `gorm:"column:job_id;index"` // 下载任务 ID，从缓存转发时为 0
+	Outcome    string `gorm:"column:outcome;type:varchar(32)"`
+	Error      string `gorm:"column:error"`
+	Duration   int64  `gorm:"column:duration"` // 从点击下载到结束的秒数
+	StartedAt  int64  `gorm:"column:started_at"`
+	FinishedAt int64  `gorm:"column:finished_at"`
+	CreatedAt  int64  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  int64  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+const (
+	DownloadHistoryOutcomePending  = "pending"
+	DownloadHistoryOutcomeCached   = "cached"
+	DownloadHistoryOutcomeDone     = DownloadJobStateDone
+	DownloadHistoryOutcomeFailed   = DownloadJobStateFailed
+	DownloadHistoryOutcomeCanceled = DownloadJobStateCanceled
+)
@@ -0,0 +1,31 @@
+package model
+
+type DownloadJob struct {
+	ID        uint   `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    int64  `gorm:"column:user_id;index"`
+	ChatID    int64  `gorm:"column:chat_id"`
+	MessageID int    `gorm:"column:message_id"`
+	InfoHash  string `gorm:"column:info_hash;type:varchar(255)"`
+	FileIndex int    `gorm:"column:file_index"`
+	State     string `gorm:"column:state;type:varchar(32);index"`
+	Error     string `gorm:"column:error"`
+	Attempts  int    `gorm:"column:attempts;default:0"` // 重启后恢复的次数
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+const (
+	DownloadJobStateQueued      = "queued"
+	DownloadJobStateDownloading = "downloading"
+	DownloadJobStateUploading   = "uploading"
+	DownloadJobStateDone        = "done"
+	DownloadJobStateFailed      = "failed"
+	DownloadJobStateCanceled    = "canceled"
+)
+
+// UnfinishedDownloadJobStates 重启后需要恢复的任务状态
+var UnfinishedDownloadJobStates = []string{
+	DownloadJobStateQueued,
+	DownloadJobStateDownloading,
+	DownloadJobStateUploading,
+}
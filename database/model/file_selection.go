@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FileSelection 一次下载选择的一组文件（文件夹等），相同的选择复用同一条记录
+// 下载任务、缓存记录和取消下载都以 SelectionFileIndex(ID) 作为文件序号
+type FileSelection struct {
+	ID          uint   `gorm:"column:id;primaryKey;autoIncrement"`
+	InfoHash    string `gorm:"column:info_hash;type:varchar(255);index"`
+	Name        string `gorm:"column:name"`
+	FileIndexes string `gorm:"column:file_indexes"` // 升序排列，逗号分隔
+}
+
+// 文件序号 -1 表示全部文件，-2 到 -7 表示各分类的文件，小于 SelectionFileIndexBase 的序号表示选择
+const SelectionFileIndexBase = -100
+
+// SelectionFileIndex 选择对应的文件序号
+func SelectionFileIndex(id uint) int {
+	return SelectionFileIndexBase - int(id)
+}
+
+// SelectionID 文件序号对应的选择 ID，不是选择时返回 false
+func SelectionID(fileIndex int) (uint, bool) {
+	if fileIndex >= SelectionFileIndexBase {
+		return 0, false
+	}
+	return uint(SelectionFileIndexBase - fileIndex), true
+}
+
+// Indexes 解析选择中的文件序号
+func (s *FileSelection) Indexes() ([]int, error) {
+	indexes := make([]int, 0)
+	for _, value := range strings.Split(s.FileIndexes, ",") {
+		if value == "" {
+			continue
+		}
+		index, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
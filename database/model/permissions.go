@@ -8,6 +8,7 @@ type Permissions struct {
 	DailyDownloadRemain   int    `gorm:"column:daily_download_remain;type:int"`
 	DailyDownloadDate     int64  `gorm:"column:daily_download_date;type:int64"`
 	FileDownloadSize      int64  `gorm:"column:file_download_size;type:int64"`
+	PremiumExpireAt       int64  `gorm:"column:premium_expire_at;type:int64"` // 高级用户到期时间，0 表示不过期
 }
 
 const (
@@ -8,4 +8,5 @@ type TorrentInfo struct {
 	NameUtf8    string `gorm:"column:name_utf8;type:varchar(255)"`
 	Length      int64  `gorm:"column:length;type:int64"`
 	IsDir       bool   `gorm:"column:is_dir"`
+	MetaInfo    []byte `gorm:"column:meta_info;type:blob"` // 完整的 .torrent 元信息（bencode），下载时无需再通过 DHT 获取
 }
@@ -5,4 +5,7 @@ type User struct {
 	UserIds  string `gorm:"column:user_ids"`
 	Premium  string `gorm:"column:premium;default:basic"`
 	Language string `gorm:"column:language;default:zh"`
+
+	Banned    bool   `gorm:"column:banned;default:false"`
+	BanReason string `gorm:"column:ban_reason"`
 }
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"bt-bot/bot"
+	"bt-bot/bot/common"
 	"bt-bot/database"
 	"bt-bot/telegram"
 	"bt-bot/torrent"
@@ -17,19 +18,31 @@ func main() {
 		log.Fatal("加载配置失败:", err)
 	}
 
-	telegram.LoadGolbalClient()
+	telegram.SetProxy(config.Bot.ProxyURL())
+	telegram.SetChannelUsername(config.Channel.Download)
+	common.SetChannels(config.Channel.Download, config.Channel.Help)
+	common.SetAdmins(config.Bot.Admins)
+
+	strategy, err := telegram.ParsePoolStrategy(config.Telegram.Strategy)
+	if err != nil {
+		log.Fatal("加载配置失败:", err)
+	}
+	telegram.LoadGolbalClient(strategy, config.Telegram.MaxInFlight)
 	defer telegram.StopAllGlobalClient()
 
 	database.InitDatabase(database.Config{
-		Path:  "database.db",
-		Debug: false,
+		Path:  config.Database.Path,
+		Debug: config.Database.Debug,
 	})
 
+	torrent.DownloadDir = config.Torrent.DownloadDir
+	torrent.MagnetTimeout = config.Torrent.MagnetTimeout
+	torrent.StallTimeout = config.Torrent.StallTimeout
 	if err := torrent.InitTorrentClient(false); err != nil {
 		log.Fatal("初始化 torrent 客户端失败:", err)
 	}
 
-	bot, err := bot.NewBot(config.Bot.Token, config.Bot.Debug)
+	bot, err := bot.NewBot(config.Bot.Token, config.Bot.Debug, config.Bot.Timeout, config.Bot.ProxyURL())
 	if err != nil {
 		log.Fatal("创建 bot 失败:", err)
 	}
@@ -0,0 +1,60 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// aviProber 解析 AVI 文件 RIFF/hdrl 列表中的 avih 主头
+type aviProber struct{}
+
+// avih 中用到的字段偏移
+const (
+	avihMicroSecPerFrame = 0
+	avihTotalFrames      = 16
+	avihWidth            = 32
+	avihHeight           = 36
+	avihMinSize          = 40
+)
+
+func (aviProber) Probe(r io.ReadSeeker, size int64) (VideoMetadata, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return VideoMetadata{}, fmt.Errorf("read riff header failed: %w", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "AVI " {
+		return VideoMetadata{}, errors.New("not an avi file")
+	}
+
+	// hdrl 列表必须是 RIFF 中的第一个块
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return VideoMetadata{}, fmt.Errorf("read hdrl list failed: %w", err)
+	}
+	if string(header[0:4]) != "LIST" || string(header[8:12]) != "hdrl" {
+		return VideoMetadata{}, errors.New("no hdrl list in avi file")
+	}
+
+	var chunk [8]byte
+	if _, err := io.ReadFull(r, chunk[:]); err != nil {
+		return VideoMetadata{}, fmt.Errorf("read avih chunk failed: %w", err)
+	}
+	avihSize := binary.LittleEndian.Uint32(chunk[4:8])
+	if string(chunk[0:4]) != "avih" || avihSize < avihMinSize {
+		return VideoMetadata{}, errors.New("no avih header in avi file")
+	}
+
+	avih := make([]byte, avihMinSize)
+	if _, err := io.ReadFull(r, avih); err != nil {
+		return VideoMetadata{}, fmt.Errorf("read avih chunk failed: %w", err)
+	}
+
+	microSecPerFrame := binary.LittleEndian.Uint32(avih[avihMicroSecPerFrame:])
+	totalFrames := binary.LittleEndian.Uint32(avih[avihTotalFrames:])
+	return VideoMetadata{
+		Width:    int(binary.LittleEndian.Uint32(avih[avihWidth:])),
+		Height:   int(binary.LittleEndian.Uint32(avih[avihHeight:])),
+		Duration: float64(microSecPerFrame) * float64(totalFrames) / 1e6,
+	}, nil
+}
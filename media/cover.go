@@ -0,0 +1,305 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 内嵌封面的大小上限，防止损坏的长度字段导致读取过多数据
+const maxCoverSize = 16 * 1024 * 1024
+
+// 内嵌封面的边长上限，压缩后很小的图片也可能声明极大的尺寸，解码时分配过多内存
+const maxCoverSide = 4096
+
+// Matroska 附件相关的 EBML 元素 ID
+const (
+	ebmlIDSeekHead     = 0x114D9B74
+	ebmlIDSeek         = 0x4DBB
+	ebmlIDSeekID       = 0x53AB
+	ebmlIDSeekPosition = 0x53AC
+	ebmlIDAttachments  = 0x1941A469
+	ebmlIDAttachedFile = 0x61A7
+	ebmlIDFileName     = 0x466E
+	ebmlIDFileMimeType = 0x4660
+	ebmlIDFileData     = 0x465C
+)
+
+// coverThumbnailer 使用视频中内嵌的封面图片
+// MP4/MOV 读取 iTunes 元数据中的 covr，MKV/WebM 读取图片附件
+type coverThumbnailer struct{}
+
+func (coverThumbnailer) Thumbnail(path string, meta VideoMetadata) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file failed: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file failed: %w", err)
+	}
+
+	var cover []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".m4v", ".mov":
+		cover, err = readMp4Cover(file, stat.Size())
+	case ".mkv", ".webm":
+		cover, err = readMatroskaCover(file, stat.Size())
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedVideo, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeCover(cover)
+}
+
+// decodeCover 先读取图片尺寸，尺寸合理时才解码
+func decodeCover(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode cover config failed: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxCoverSide || config.Height > maxCoverSide {
+		return nil, fmt.Errorf("invalid cover size %dx%d", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode cover failed: %w", err)
+	}
+	return img, nil
+}
+
+// readMp4Cover 读取 moov/udta/meta/ilst/covr/data 中的图片
+func readMp4Cover(r io.ReadSeeker, size int64) ([]byte, error) {
+	current := atom{Offset: 0, Size: size}
+	for _, atomType := range []string{"moov", "udta", "meta", "ilst", "covr", "data"} {
+		start := current.Offset
+		if current.Type == "meta" {
+			// MP4 的 meta 是 full box，内容前有 4 字节 version/flags，QuickTime 的没有
+			header, err := readAtomData(r, current, 8)
+			if err != nil {
+				return nil, err
+			}
+			if string(header[4:8]) != "hdlr" {
+				start += 4
+			}
+		}
+
+		next, err := findAtom(r, start, current.Offset+current.Size, atomType)
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+
+	// data 内容：类型(4) 语言(4) 图片数据
+	if current.Size <= 8 || current.Size-8 > maxCoverSize {
+		return nil, errors.New("invalid cover size")
+	}
+	data, err := readAtomData(r, current, current.Size)
+	if err != nil {
+		return nil, err
+	}
+	return data[8:], nil
+}
+
+// readMatroskaCover 读取 Attachments 中的图片附件，优先使用文件名以 cover 开头的附件
+// Attachments 可能位于 Cluster 之后，此时通过 SeekHead 定位
+func readMatroskaCover(rs io.ReadSeeker, size int64) ([]byte, error) {
+	r := newEBMLReader(rs)
+
+	id, headerSize, err := r.readElementHeader()
+	if err != nil || id != ebmlIDHeader || headerSize < 0 {
+		return nil, errors.New("not a matroska file")
+	}
+	if err := r.seek(r.pos + headerSize); err != nil {
+		return nil, err
+	}
+	id, segmentSize, err := r.readElementHeader()
+	if err != nil || id != ebmlIDSegment {
+		return nil, errors.New("no segment in matroska file")
+	}
+	segmentStart := r.pos
+	segmentEnd := size
+	if segmentSize != ebmlUnknownSize {
+		segmentEnd = min(size, r.pos+segmentSize)
+	}
+
+	attachmentsPos := int64(-1)
+	for r.pos < segmentEnd {
+		id, elementSize, err := r.readElementHeader()
+		if err != nil {
+			return nil, err
+		}
+		if id == ebmlIDCluster || elementSize == ebmlUnknownSize || r.pos+elementSize > segmentEnd {
+			break
+		}
+		end := r.pos + elementSize
+
+		switch id {
+		case ebmlIDAttachments:
+			return readMatroskaAttachments(r, end)
+		case ebmlIDSeekHead:
+			if pos, err := readMatroskaSeekPosition(r, end, ebmlIDAttachments); err == nil {
+				attachmentsPos = segmentStart + pos
+			}
+		}
+		if err := r.seek(end); err != nil {
+			return nil, err
+		}
+	}
+
+	if attachmentsPos < 0 || attachmentsPos >= segmentEnd {
+		return nil, errors.New("no attachments in matroska file")
+	}
+	if err := r.seek(attachmentsPos); err != nil {
+		return nil, err
+	}
+	id, elementSize, err := r.readElementHeader()
+	if err != nil || id != ebmlIDAttachments || elementSize < 0 || r.pos+elementSize > segmentEnd {
+		return nil, errors.New("invalid attachments position in matroska file")
+	}
+	return readMatroskaAttachments(r, r.pos+elementSize)
+}
+
+// readMatroskaSeekPosition 在 SeekHead 中查找元素相对 Segment 内容起始的位置
+func readMatroskaSeekPosition(r *ebmlReader, end int64, target uint32) (int64, error) {
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return 0, err
+		}
+		if size < 0 || r.pos+size > end {
+			return 0, errors.New("invalid element size in matroska seek head")
+		}
+		next := r.pos + size
+
+		if id == ebmlIDSeek {
+			var seekID uint64
+			position := int64(-1)
+			for r.pos < next {
+				childID, childSize, err := r.readElementHeader()
+				if err != nil {
+					return 0, err
+				}
+				if childSize < 0 || r.pos+childSize > next {
+					return 0, errors.New("invalid element size in matroska seek")
+				}
+				childEnd := r.pos + childSize
+				switch childID {
+				case ebmlIDSeekID:
+					if seekID, err = r.readUint(childSize); err != nil {
+						return 0, err
+					}
+				case ebmlIDSeekPosition:
+					value, err := r.readUint(childSize)
+					if err != nil {
+						return 0, err
+					}
+					position = int64(value)
+				}
+				if err := r.seek(childEnd); err != nil {
+					return 0, err
+				}
+			}
+			if uint32(seekID) == target && position >= 0 {
+				return position, nil
+			}
+		}
+		if err := r.seek(next); err != nil {
+			return 0, err
+		}
+	}
+	return 0, errors.New("element not found in seek head")
+}
+
+func readMatroskaAttachments(r *ebmlReader, end int64) ([]byte, error) {
+	var fallback []byte
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 || r.pos+size > end {
+			return nil, errors.New("invalid element size in matroska attachments")
+		}
+		next := r.pos + size
+
+		if id == ebmlIDAttachedFile {
+			name, mimeType, data, err := readMatroskaAttachedFile(r, next)
+			if err != nil {
+				return nil, err
+			}
+			if data != nil && strings.HasPrefix(mimeType, "image/") {
+				if strings.HasPrefix(strings.ToLower(name), "cover") {
+					return data, nil
+				}
+				if fallback == nil {
+					fallback = data
+				}
+			}
+		}
+		if err := r.seek(next); err != nil {
+			return nil, err
+		}
+	}
+
+	if fallback == nil {
+		return nil, errors.New("no image attachment in matroska file")
+	}
+	return fallback, nil
+}
+
+// readMatroskaAttachedFile 读取附件，非图片附件（通常是字体）不读取数据
+func readMatroskaAttachedFile(r *ebmlReader, end int64) (name, mimeType string, data []byte, err error) {
+	var dataPos, dataSize int64 = -1, 0
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return "", "", nil, err
+		}
+		if size < 0 || r.pos+size > end {
+			return "", "", nil, errors.New("invalid element size in matroska attached file")
+		}
+		next := r.pos + size
+
+		switch id {
+		case ebmlIDFileName, ebmlIDFileMimeType:
+			value, err := r.read(min(size, 256))
+			if err != nil {
+				return "", "", nil, err
+			}
+			if id == ebmlIDFileName {
+				name = string(value)
+			} else {
+				mimeType = string(value)
+			}
+		case ebmlIDFileData:
+			dataPos, dataSize = r.pos, size
+		}
+		if err := r.seek(next); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	if dataPos < 0 || !strings.HasPrefix(mimeType, "image/") || dataSize > maxCoverSize {
+		return name, mimeType, nil, nil
+	}
+	if err := r.seek(dataPos); err != nil {
+		return "", "", nil, err
+	}
+	if data, err = r.read(dataSize); err != nil {
+		return "", "", nil, err
+	}
+	return name, mimeType, data, r.seek(end)
+}
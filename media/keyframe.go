@@ -0,0 +1,148 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Eyevinn/mp4ff/avc"
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+// 关键帧的最大字节数，采样大小来自 stsz，需要限制以免分配过大的内存
+const maxKeyframeSize = 32 * 1024 * 1024
+
+// keyframeThumbnailer 从 MP4/MOV 中取出一个关键帧交给帧解码器
+type keyframeThumbnailer struct{}
+
+func (keyframeThumbnailer) Thumbnail(path string, meta VideoMetadata) (image.Image, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".m4v", ".mov":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedVideo, filepath.Ext(path))
+	}
+
+	decoder := currentFrameDecoder()
+	frame, err := readMp4Keyframe(path, decoder)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.DecodeFrame(frame)
+}
+
+// readMp4Keyframe 读取视频轨道中约 10% 处的关键帧，避开片头的黑屏
+// 解码器不支持视频的编码格式时不读取采样数据
+func readMp4Keyframe(path string, decoder FrameDecoder) (Frame, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Frame{}, fmt.Errorf("open file failed: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Frame{}, fmt.Errorf("stat file failed: %w", err)
+	}
+	fileSize := info.Size()
+
+	mp4File, err := mp4.DecodeFile(file, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+	if err != nil {
+		return Frame{}, fmt.Errorf("decode mp4 failed: %w", err)
+	}
+	if mp4File.Moov == nil {
+		return Frame{}, errors.New("no moov box in mp4 file")
+	}
+
+	for _, trak := range mp4File.Moov.Traks {
+		if trak.Mdia == nil || trak.Mdia.Hdlr == nil || trak.Mdia.Hdlr.HandlerType != "vide" {
+			continue
+		}
+		if trak.Mdia.Minf == nil || trak.Mdia.Minf.Stbl == nil {
+			continue
+		}
+		stbl := trak.Mdia.Minf.Stbl
+		if stbl.Stsd == nil || stbl.Stsz == nil || stbl.Stsc == nil || len(stbl.Stsd.Children) == 0 {
+			continue
+		}
+
+		sampleEntry := stbl.Stsd.Children[0]
+		codec, err := sampleEntryCodec(sampleEntry)
+		if err != nil {
+			return Frame{}, err
+		}
+		if !decoderSupports(decoder, codec) {
+			return Frame{}, fmt.Errorf("%w: %s", ErrUnsupportedCodec, codec)
+		}
+
+		nrSamples := stbl.Stsz.GetNrSamples()
+		if nrSamples == 0 {
+			continue
+		}
+		sampleNr := nrSamples/10 + 1
+		if stbl.Stss != nil && len(stbl.Stss.SampleNumber) > 0 {
+			sampleNr = stbl.Stss.SampleNumber[len(stbl.Stss.SampleNumber)/10]
+		}
+
+		ranges, err := trak.GetRangesForSampleInterval(sampleNr, sampleNr)
+		if err != nil || len(ranges) != 1 {
+			return Frame{}, fmt.Errorf("locate keyframe failed: %v", err)
+		}
+		size, offset := ranges[0].Size, ranges[0].Offset
+		if size > maxKeyframeSize || offset > uint64(fileSize) || size > uint64(fileSize)-offset {
+			return Frame{}, fmt.Errorf("invalid keyframe size %d at offset %d", size, offset)
+		}
+		data := make([]byte, size)
+		if _, err := file.ReadAt(data, int64(ranges[0].Offset)); err != nil {
+			return Frame{}, fmt.Errorf("read keyframe failed: %w", err)
+		}
+		return keyframe(sampleEntry, codec, data), nil
+	}
+
+	return Frame{}, errors.New("no video track found in mp4 file")
+}
+
+// sampleEntryCodec 采样描述对应的编码格式
+func sampleEntryCodec(sampleEntry mp4.Box) (string, error) {
+	switch sampleEntry.Type() {
+	case "jpeg", "mjpa":
+		return CodecMJPEG, nil
+	case "avc1", "avc3":
+		return CodecH264, nil
+	case "hvc1", "hev1":
+		return CodecHEVC, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedCodec, sampleEntry.Type())
+}
+
+// keyframe 根据采样描述把采样数据转换为解码器可以直接使用的帧
+func keyframe(sampleEntry mp4.Box, codec string, sample []byte) Frame {
+	if codec == CodecMJPEG {
+		return Frame{Codec: codec, Data: sample}
+	}
+
+	var parameterSets [][]byte
+	if entry, ok := sampleEntry.(*mp4.VisualSampleEntryBox); ok {
+		if codec == CodecH264 && entry.AvcC != nil {
+			parameterSets = append(append(parameterSets, entry.AvcC.SPSnalus...), entry.AvcC.PPSnalus...)
+		}
+		if codec == CodecHEVC && entry.HvcC != nil {
+			for _, array := range entry.HvcC.NaluArrays {
+				parameterSets = append(parameterSets, array.Nalus...)
+			}
+		}
+	}
+	return Frame{Codec: codec, Data: annexB(parameterSets, sample)}
+}
+
+// annexB 将参数集和长度前缀格式的采样拼接为 Annex B 字节流
+func annexB(parameterSets [][]byte, sample []byte) []byte {
+	startCode := []byte{0, 0, 0, 1}
+	var stream []byte
+	for _, nalu := range parameterSets {
+		stream = append(append(stream, startCode...), nalu...)
+	}
+	return append(stream, avc.ConvertSampleToByteStream(sample)...)
+}
@@ -0,0 +1,66 @@
+package media
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+// testMp4WithSampleSize 只有一个采样的 MP4，采样大小由 stsz 指定，不包含采样数据
+func testMp4WithSampleSize(t *testing.T, sampleSize uint32) string {
+	init := mp4.CreateEmptyInit()
+	trak := init.AddEmptyTrack(90000, "video", "und")
+	stbl := trak.Mdia.Minf.Stbl
+	stbl.Stsd.AddChild(mp4.CreateVisualSampleEntryBox("avc1", 640, 360, nil))
+	stbl.Stsz.SampleNumber = 1
+	stbl.Stsz.SampleSize = []uint32{sampleSize}
+	if err := stbl.Stsc.AddEntry(1, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	stbl.Stco.ChunkOffset = []uint32{0}
+
+	path := filepath.Join(t.TempDir(), "sample.mp4")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := init.Encode(file); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// anyFrameDecoder 支持所有编码格式的解码器，只用于读取关键帧
+type anyFrameDecoder struct{}
+
+func (anyFrameDecoder) DecodeFrame(frame Frame) (image.Image, error) {
+	return nil, ErrUnsupportedCodec
+}
+
+func TestReadMp4KeyframeRejectsLargeSamples(t *testing.T) {
+	if _, err := readMp4Keyframe(testMp4WithSampleSize(t, 0xFFFFFFF0), anyFrameDecoder{}); err == nil {
+		t.Error("expected error for a sample larger than the limit")
+	}
+	// 未超过上限，但超出文件长度
+	if _, err := readMp4Keyframe(testMp4WithSampleSize(t, maxKeyframeSize), anyFrameDecoder{}); err == nil {
+		t.Error("expected error for a sample beyond the end of file")
+	}
+	// 文件开头的 16 字节在文件范围内，可以读取
+	frame, err := readMp4Keyframe(testMp4WithSampleSize(t, 16), anyFrameDecoder{})
+	if err != nil || len(frame.Data) < 16 {
+		t.Errorf("readMp4Keyframe = %d bytes, %v", len(frame.Data), err)
+	}
+}
+
+func TestReadMp4KeyframeSkipsUnsupportedCodec(t *testing.T) {
+	// 内置解码器只支持 MJPEG，H.264 视频不读取采样数据，即使采样大小超出文件
+	_, err := readMp4Keyframe(testMp4WithSampleSize(t, maxKeyframeSize), mjpegFrameDecoder{})
+	if !errors.Is(err, ErrUnsupportedCodec) {
+		t.Errorf("readMp4Keyframe error = %v, want ErrUnsupportedCodec", err)
+	}
+}
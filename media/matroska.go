@@ -0,0 +1,344 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Matroska/WebM 中用到的 EBML 元素 ID
+const (
+	ebmlIDHeader        = 0x1A45DFA3
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549A966
+	ebmlIDTimecodeScale = 0x2AD7B1
+	ebmlIDDuration      = 0x4489
+	ebmlIDTracks        = 0x1654AE6B
+	ebmlIDTrackEntry    = 0xAE
+	ebmlIDTrackType     = 0x83
+	ebmlIDVideo         = 0xE0
+	ebmlIDPixelWidth    = 0xB0
+	ebmlIDPixelHeight   = 0xBA
+	ebmlIDCluster       = 0x1F43B675
+
+	// 未知长度，只允许出现在 Segment 和 Cluster 上
+	ebmlUnknownSize = -1
+
+	// 单次读取的最大字节数，长度来自文件，需要限制以免分配过大的内存，最大的读取是附件中的封面图片
+	ebmlMaxReadSize = maxCoverSize
+
+	matroskaTrackTypeVideo = 1
+	// TimecodeScale 的默认值，单位纳秒
+	matroskaDefaultTimecodeScale = 1000000
+)
+
+// matroskaProber 解析 Matroska/WebM 的 EBML 结构
+// 读取 Segment/Info 中的时长和第一条视频轨道的像素尺寸，遇到 Cluster 即停止
+type matroskaProber struct{}
+
+// ebmlReader 在 bufio 之上记录当前读取位置，跳过大元素时直接 Seek
+type ebmlReader struct {
+	rs  io.ReadSeeker
+	br  *bufio.Reader
+	pos int64
+}
+
+func newEBMLReader(rs io.ReadSeeker) *ebmlReader {
+	return &ebmlReader{rs: rs, br: bufio.NewReader(rs)}
+}
+
+func (r *ebmlReader) readByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	r.pos++
+	return b, nil
+}
+
+func (r *ebmlReader) read(n int64) ([]byte, error) {
+	if n < 0 || n > ebmlMaxReadSize {
+		return nil, fmt.Errorf("invalid EBML element size %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r.br, data); err != nil {
+		return nil, err
+	}
+	r.pos += n
+	return data, nil
+}
+
+func (r *ebmlReader) seek(pos int64) error {
+	if pos-r.pos >= 0 && pos-r.pos <= int64(r.br.Buffered()) {
+		_, err := r.br.Discard(int(pos - r.pos))
+		r.pos = pos
+		return err
+	}
+	if _, err := r.rs.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	r.br.Reset(r.rs)
+	r.pos = pos
+	return nil
+}
+
+// readVint 读取变长整数，keepMarker 为 true 时保留长度标记位（元素 ID 的写法）
+func (r *ebmlReader) readVint(maxLen int, keepMarker bool) (value int64, allOnes bool, err error) {
+	first, err := r.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	length := 1
+	for mask := byte(0x80); length <= 8 && first&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > maxLen {
+		return 0, false, fmt.Errorf("invalid EBML vint at offset %d", r.pos-1)
+	}
+
+	marker := byte(0x80) >> (length - 1)
+	value = int64(first)
+	if !keepMarker {
+		value = int64(first &^ marker)
+	}
+	// 除标记位外全部为 1 表示未知长度
+	allOnes = first&(marker-1) == marker-1
+	for i := 1; i < length; i++ {
+		b, err := r.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		value = value<<8 | int64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+	return value, allOnes, nil
+}
+
+// readElementHeader 读取元素 ID 和内容长度，长度未知时返回 ebmlUnknownSize
+func (r *ebmlReader) readElementHeader() (id uint32, size int64, err error) {
+	rawID, _, err := r.readVint(4, true)
+	if err != nil {
+		return 0, 0, err
+	}
+	size, unknown, err := r.readVint(8, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if unknown {
+		size = ebmlUnknownSize
+	}
+	return uint32(rawID), size, nil
+}
+
+func (r *ebmlReader) readUint(size int64) (uint64, error) {
+	if size < 1 || size > 8 {
+		return 0, fmt.Errorf("invalid EBML uint size %d", size)
+	}
+	data, err := r.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+func (r *ebmlReader) readFloat(size int64) (float64, error) {
+	if size != 4 && size != 8 {
+		return 0, fmt.Errorf("invalid EBML float size %d", size)
+	}
+	data, err := r.read(size)
+	if err != nil {
+		return 0, err
+	}
+	if size == 4 {
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+}
+
+func (matroskaProber) Probe(rs io.ReadSeeker, size int64) (VideoMetadata, error) {
+	r := newEBMLReader(rs)
+
+	id, headerSize, err := r.readElementHeader()
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("read EBML header failed: %w", err)
+	}
+	if id != ebmlIDHeader || headerSize < 0 {
+		return VideoMetadata{}, errors.New("not a matroska file")
+	}
+	if err := r.seek(r.pos + headerSize); err != nil {
+		return VideoMetadata{}, err
+	}
+
+	id, segmentSize, err := r.readElementHeader()
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("read segment failed: %w", err)
+	}
+	if id != ebmlIDSegment {
+		return VideoMetadata{}, errors.New("no segment in matroska file")
+	}
+	segmentEnd := size
+	if segmentSize != ebmlUnknownSize {
+		segmentEnd = min(size, r.pos+segmentSize)
+	}
+
+	var meta VideoMetadata
+	var foundInfo, foundTracks bool
+	for r.pos < segmentEnd && !(foundInfo && foundTracks) {
+		id, elementSize, err := r.readElementHeader()
+		if err != nil {
+			return VideoMetadata{}, fmt.Errorf("read segment child failed: %w", err)
+		}
+		if id == ebmlIDCluster {
+			// 媒体数据开始，Info 和 Tracks 只会出现在它之前
+			break
+		}
+		if elementSize == ebmlUnknownSize || r.pos+elementSize > segmentEnd {
+			return VideoMetadata{}, fmt.Errorf("invalid element size at offset %d", r.pos)
+		}
+		end := r.pos + elementSize
+
+		switch id {
+		case ebmlIDInfo:
+			if meta.Duration, err = readMatroskaInfo(r, end); err != nil {
+				return VideoMetadata{}, err
+			}
+			foundInfo = true
+		case ebmlIDTracks:
+			if meta.Width, meta.Height, err = readMatroskaTracks(r, end); err != nil {
+				return VideoMetadata{}, err
+			}
+			foundTracks = true
+		}
+		if err := r.seek(end); err != nil {
+			return VideoMetadata{}, err
+		}
+	}
+
+	if meta.Width == 0 {
+		return VideoMetadata{}, errors.New("no video track found in matroska file")
+	}
+	return meta, nil
+}
+
+// readMatroskaInfo 读取 Segment/Info，返回时长（秒）
+func readMatroskaInfo(r *ebmlReader, end int64) (float64, error) {
+	timecodeScale := uint64(matroskaDefaultTimecodeScale)
+	var duration float64
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return 0, err
+		}
+		if size < 0 || r.pos+size > end {
+			return 0, errors.New("invalid element size in matroska info")
+		}
+		next := r.pos + size
+
+		switch id {
+		case ebmlIDTimecodeScale:
+			if timecodeScale, err = r.readUint(size); err != nil {
+				return 0, err
+			}
+		case ebmlIDDuration:
+			if duration, err = r.readFloat(size); err != nil {
+				return 0, err
+			}
+		}
+		if err := r.seek(next); err != nil {
+			return 0, err
+		}
+	}
+
+	if math.IsNaN(duration) || duration < 0 {
+		return 0, nil
+	}
+	return duration * float64(timecodeScale) / 1e9, nil
+}
+
+// readMatroskaTracks 读取 Segment/Tracks，返回第一条视频轨道的像素尺寸
+func readMatroskaTracks(r *ebmlReader, end int64) (width, height int, err error) {
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return 0, 0, err
+		}
+		if size < 0 || r.pos+size > end {
+			return 0, 0, errors.New("invalid element size in matroska tracks")
+		}
+		next := r.pos + size
+
+		if id == ebmlIDTrackEntry {
+			isVideo, w, h, err := readMatroskaTrackEntry(r, next)
+			if err != nil {
+				return 0, 0, err
+			}
+			if isVideo && w > 0 && h > 0 {
+				return w, h, nil
+			}
+		}
+		if err := r.seek(next); err != nil {
+			return 0, 0, err
+		}
+	}
+	return 0, 0, nil
+}
+
+func readMatroskaTrackEntry(r *ebmlReader, end int64) (isVideo bool, width, height int, err error) {
+	for r.pos < end {
+		id, size, err := r.readElementHeader()
+		if err != nil {
+			return false, 0, 0, err
+		}
+		if size < 0 || r.pos+size > end {
+			return false, 0, 0, errors.New("invalid element size in matroska track")
+		}
+		next := r.pos + size
+
+		switch id {
+		case ebmlIDTrackType:
+			trackType, err := r.readUint(size)
+			if err != nil {
+				return false, 0, 0, err
+			}
+			isVideo = trackType == matroskaTrackTypeVideo
+		case ebmlIDVideo:
+			for r.pos < next {
+				childID, childSize, err := r.readElementHeader()
+				if err != nil {
+					return false, 0, 0, err
+				}
+				if childSize < 0 || r.pos+childSize > next {
+					return false, 0, 0, errors.New("invalid element size in matroska video")
+				}
+				childEnd := r.pos + childSize
+				switch childID {
+				case ebmlIDPixelWidth, ebmlIDPixelHeight:
+					value, err := r.readUint(childSize)
+					if err != nil {
+						return false, 0, 0, err
+					}
+					if childID == ebmlIDPixelWidth {
+						width = int(value)
+					} else {
+						height = int(value)
+					}
+				}
+				if err := r.seek(childEnd); err != nil {
+					return false, 0, 0, err
+				}
+			}
+		}
+		if err := r.seek(next); err != nil {
+			return false, 0, 0, err
+		}
+	}
+	return isVideo, width, height, nil
+}
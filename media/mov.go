@@ -0,0 +1,206 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// movProber 解析 QuickTime 文件的原子（atom）结构
+// 只读取 moov/mvhd 的时长和第一条视频轨道 trak/tkhd 的尺寸，跳过其余原子
+type movProber struct{}
+
+// atom QuickTime 原子头，Offset 为原子内容的起始位置
+type atom struct {
+	Type   string
+	Offset int64
+	Size   int64
+}
+
+func (movProber) Probe(r io.ReadSeeker, size int64) (VideoMetadata, error) {
+	moov, err := findAtom(r, 0, size, "moov")
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+
+	var meta VideoMetadata
+	children, err := readAtoms(r, moov.Offset, moov.Offset+moov.Size)
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	for _, child := range children {
+		switch child.Type {
+		case "mvhd":
+			if meta.Duration, err = readMvhdDuration(r, child); err != nil {
+				return VideoMetadata{}, err
+			}
+		case "trak":
+			if meta.Width > 0 {
+				continue
+			}
+			width, height, ok, err := readVideoTrak(r, child)
+			if err != nil {
+				return VideoMetadata{}, err
+			}
+			if ok {
+				meta.Width, meta.Height = width, height
+			}
+		}
+	}
+
+	if meta.Width == 0 {
+		return VideoMetadata{}, errors.New("no video track found in mov file")
+	}
+	return meta, nil
+}
+
+// readAtoms 读取 [start, end) 范围内同一层级的所有原子头
+func readAtoms(r io.ReadSeeker, start, end int64) ([]atom, error) {
+	var atoms []atom
+	var header [16]byte
+	for offset := start; offset+8 <= end; {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, fmt.Errorf("read atom header failed: %w", err)
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			// 原子一直延续到父级末尾
+			size = end - offset
+		case 1:
+			// 64 位扩展长度
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, fmt.Errorf("read atom header failed: %w", err)
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize || offset+size > end {
+			return nil, fmt.Errorf("invalid atom size %d at offset %d", size, offset)
+		}
+
+		atoms = append(atoms, atom{
+			Type:   string(header[4:8]),
+			Offset: offset + headerSize,
+			Size:   size - headerSize,
+		})
+		offset += size
+	}
+	return atoms, nil
+}
+
+// findAtom 在 [start, end) 范围内查找指定类型的原子
+func findAtom(r io.ReadSeeker, start, end int64, atomType string) (atom, error) {
+	atoms, err := readAtoms(r, start, end)
+	if err != nil {
+		return atom{}, err
+	}
+	for _, a := range atoms {
+		if a.Type == atomType {
+			return a, nil
+		}
+	}
+	return atom{}, fmt.Errorf("atom %q not found", atomType)
+}
+
+// readAtomData 读取原子内容的前 n 个字节
+func readAtomData(r io.ReadSeeker, a atom, n int64) ([]byte, error) {
+	if a.Size < n {
+		return nil, fmt.Errorf("atom %q too short", a.Type)
+	}
+	if _, err := r.Seek(a.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("read atom %q failed: %w", a.Type, err)
+	}
+	return data, nil
+}
+
+// readMvhdDuration 从 mvhd 读取影片时长（秒）
+func readMvhdDuration(r io.ReadSeeker, mvhd atom) (float64, error) {
+	data, err := readAtomData(r, mvhd, 4)
+	if err != nil {
+		return 0, err
+	}
+
+	var timescale uint32
+	var duration uint64
+	if data[0] == 1 {
+		// version 1: creation(8) modification(8) timescale(4) duration(8)
+		if data, err = readAtomData(r, mvhd, 32); err != nil {
+			return 0, err
+		}
+		timescale = binary.BigEndian.Uint32(data[20:24])
+		duration = binary.BigEndian.Uint64(data[24:32])
+	} else {
+		// version 0: creation(4) modification(4) timescale(4) duration(4)
+		if data, err = readAtomData(r, mvhd, 20); err != nil {
+			return 0, err
+		}
+		timescale = binary.BigEndian.Uint32(data[12:16])
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	}
+
+	if timescale == 0 {
+		return 0, nil
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+// readVideoTrak 如果 trak 是视频轨道，从 tkhd 读取画面尺寸
+func readVideoTrak(r io.ReadSeeker, trak atom) (width, height int, ok bool, err error) {
+	children, err := readAtoms(r, trak.Offset, trak.Offset+trak.Size)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	var tkhd, mdia *atom
+	for i := range children {
+		switch children[i].Type {
+		case "tkhd":
+			tkhd = &children[i]
+		case "mdia":
+			mdia = &children[i]
+		}
+	}
+	if tkhd == nil || mdia == nil {
+		return 0, 0, false, nil
+	}
+
+	hdlr, err := findAtom(r, mdia.Offset, mdia.Offset+mdia.Size, "hdlr")
+	if err != nil {
+		return 0, 0, false, nil
+	}
+	// version/flags(4) component type(4) component subtype(4)
+	data, err := readAtomData(r, hdlr, 12)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if string(data[8:12]) != "vide" {
+		return 0, 0, false, nil
+	}
+
+	data, err = readAtomData(r, *tkhd, 4)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	// 宽高是 tkhd 末尾两个 16.16 定点数，version 1 的时间字段多 12 字节
+	sizeOffset := int64(76)
+	if data[0] == 1 {
+		sizeOffset = 88
+	}
+	if data, err = readAtomData(r, *tkhd, sizeOffset+8); err != nil {
+		return 0, 0, false, err
+	}
+	width = int(binary.BigEndian.Uint32(data[sizeOffset:sizeOffset+4]) >> 16)
+	height = int(binary.BigEndian.Uint32(data[sizeOffset+4:sizeOffset+8]) >> 16)
+	return width, height, width > 0 && height > 0, nil
+}
@@ -0,0 +1,40 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Eyevinn/mp4ff/mp4"
+)
+
+// mp4Prober 使用 mp4ff 解析 MP4 文件的 moov 结构
+type mp4Prober struct{}
+
+func (mp4Prober) Probe(r io.ReadSeeker, size int64) (VideoMetadata, error) {
+	mp4File, err := mp4.DecodeFile(r, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("decode mp4 failed: %w", err)
+	}
+	if mp4File.Moov == nil {
+		return VideoMetadata{}, errors.New("no moov box in mp4 file")
+	}
+
+	var meta VideoMetadata
+	if mvhd := mp4File.Moov.Mvhd; mvhd != nil && mvhd.Timescale > 0 {
+		meta.Duration = float64(mvhd.Duration) / float64(mvhd.Timescale)
+	}
+
+	for _, track := range mp4File.Moov.Traks {
+		if track.Mdia == nil || track.Mdia.Hdlr == nil || track.Tkhd == nil {
+			continue
+		}
+		if track.Mdia.Hdlr.HandlerType == "vide" {
+			meta.Width = int(track.Tkhd.Width >> 16)
+			meta.Height = int(track.Tkhd.Height >> 16)
+			return meta, nil
+		}
+	}
+
+	return VideoMetadata{}, errors.New("no video track found in mp4 file")
+}
@@ -0,0 +1,187 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"sync"
+)
+
+// Telegram 对文档缩略图的限制：JPEG 格式，边长不超过 320，大小不超过 200KB
+const (
+	ThumbnailMaxSide  = 320
+	ThumbnailMaxBytes = 200 * 1024
+)
+
+// Thumbnailer 为视频生成缩略图，无法生成时返回错误，交给下一个实现处理
+type Thumbnailer interface {
+	Thumbnail(path string, meta VideoMetadata) (image.Image, error)
+}
+
+// Frame 从容器中取出的一帧编码数据
+// H.264/HEVC 为带参数集的 Annex B 字节流，MJPEG 为完整的 JPEG 图片
+type Frame struct {
+	Codec string
+	Data  []byte
+}
+
+// 关键帧的编码格式
+const (
+	CodecH264  = "h264"
+	CodecHEVC  = "hevc"
+	CodecMJPEG = "mjpeg"
+)
+
+// ErrUnsupportedCodec 帧解码器不支持该编码格式
+var ErrUnsupportedCodec = errors.New("unsupported codec")
+
+// FrameDecoder 将关键帧解码为图片
+// 纯 Go 无法解码 H.264/HEVC，内置实现只支持 MJPEG，可通过 SetFrameDecoder 接入 ffmpeg 等外部解码器
+type FrameDecoder interface {
+	DecodeFrame(frame Frame) (image.Image, error)
+}
+
+// CodecSupporter 帧解码器可以实现该接口声明支持的编码格式，未实现时视为支持所有格式
+// 不支持的编码格式会跳过关键帧，不读取帧数据
+type CodecSupporter interface {
+	SupportsCodec(codec string) bool
+}
+
+func decoderSupports(decoder FrameDecoder, codec string) bool {
+	supporter, ok := decoder.(CodecSupporter)
+	return !ok || supporter.SupportsCodec(codec)
+}
+
+var (
+	thumbnailMu sync.RWMutex
+	// 按顺序尝试：关键帧、内嵌封面、生成的标题卡
+	thumbnailers = []Thumbnailer{keyframeThumbnailer{}, coverThumbnailer{}, titleCardThumbnailer{}}
+	frameDecoder = FrameDecoder(mjpegFrameDecoder{})
+)
+
+// RegisterThumbnailer 注册缩略图生成器，优先于已有的生成器
+func RegisterThumbnailer(t Thumbnailer) {
+	thumbnailMu.Lock()
+	defer thumbnailMu.Unlock()
+	thumbnailers = append([]Thumbnailer{t}, thumbnailers...)
+}
+
+// SetFrameDecoder 替换关键帧解码器
+func SetFrameDecoder(d FrameDecoder) {
+	thumbnailMu.Lock()
+	defer thumbnailMu.Unlock()
+	frameDecoder = d
+}
+
+func currentFrameDecoder() FrameDecoder {
+	thumbnailMu.RLock()
+	defer thumbnailMu.RUnlock()
+	return frameDecoder
+}
+
+// VideoThumbnail 为视频生成符合 Telegram 限制的 JPEG 缩略图
+func VideoThumbnail(path string, meta VideoMetadata) ([]byte, error) {
+	thumbnailMu.RLock()
+	chain := append([]Thumbnailer(nil), thumbnailers...)
+	thumbnailMu.RUnlock()
+
+	var errs []error
+	for _, t := range chain {
+		img, err := safeThumbnail(t, path, meta)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data, err := encodeThumbnail(img)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return data, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// safeThumbnail 调用生成器，损坏的文件不应导致程序崩溃
+func safeThumbnail(t Thumbnailer, path string, meta VideoMetadata) (img image.Image, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			img, err = nil, fmt.Errorf("thumbnailer panicked: %v", r)
+		}
+	}()
+	img, err = t.Thumbnail(path, meta)
+	if err == nil && (img == nil || img.Bounds().Empty()) {
+		err = errors.New("empty thumbnail")
+	}
+	return img, err
+}
+
+// encodeThumbnail 缩放到限制尺寸内并编码为 JPEG，超过大小限制时降低质量
+func encodeThumbnail(img image.Image) ([]byte, error) {
+	img = scaleToFit(img, ThumbnailMaxSide)
+
+	var buf bytes.Buffer
+	for _, quality := range []int{85, 70, 50, 30} {
+		buf.Reset()
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		if buf.Len() <= ThumbnailMaxBytes {
+			return buf.Bytes(), nil
+		}
+	}
+	return nil, errors.New("thumbnail too large")
+}
+
+// scaleToFit 按比例缩小图片使最长边不超过 maxSide，每个目标像素取对应源区域的平均值
+func scaleToFit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= maxSide && srcH <= maxSide {
+		return img
+	}
+
+	dstW, dstH := maxSide, max(1, srcH*maxSide/srcW)
+	if srcH > srcW {
+		dstW, dstH = max(1, srcW*maxSide/srcH), maxSide
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, b = r+uint64(cr), g+uint64(cg), b+uint64(cb)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(b / n >> 8), A: 0xFF})
+		}
+	}
+	return dst
+}
+
+// mjpegFrameDecoder 内置的帧解码器，MJPEG 的每一帧都是完整的 JPEG 图片
+type mjpegFrameDecoder struct{}
+
+func (mjpegFrameDecoder) SupportsCodec(codec string) bool {
+	return codec == CodecMJPEG
+}
+
+func (mjpegFrameDecoder) DecodeFrame(frame Frame) (image.Image, error) {
+	if frame.Codec != CodecMJPEG {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, frame.Codec)
+	}
+	return jpeg.Decode(bytes.NewReader(frame.Data))
+}
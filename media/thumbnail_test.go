@@ -0,0 +1,124 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testCoverPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 640, 360))
+	for y := 0; y < 360; y++ {
+		for x := 0; x < 640; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: 0xFF, A: 0xFF})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// decodeThumbnail 校验缩略图符合 Telegram 限制并返回中心像素颜色
+func decodeThumbnail(t *testing.T, data []byte) color.Color {
+	t.Helper()
+	if len(data) > ThumbnailMaxBytes {
+		t.Fatalf("thumbnail too large: %d bytes", len(data))
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("thumbnail is not a jpeg: %v", err)
+	}
+	bounds := img.Bounds()
+	if max(bounds.Dx(), bounds.Dy()) > ThumbnailMaxSide {
+		t.Fatalf("thumbnail too big: %v", bounds)
+	}
+	return img.At(bounds.Dx()/2, bounds.Dy()/2)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xE000 && g < 0x2000 && b < 0x2000
+}
+
+func TestVideoThumbnailCover(t *testing.T) {
+	cover := testCoverPNG(t)
+	dir := t.TempDir()
+
+	dataAtom := movAtom("data", append(make([]byte, 8), cover...))
+	ilst := movAtom("ilst", movAtom("covr", dataAtom))
+	mov := testMov(movAtom("udta", movAtom("meta", make([]byte, 4), movAtom("hdlr", make([]byte, 24)), ilst)))
+
+	attachments := ebmlElement([]byte{0x19, 0x41, 0xA4, 0x69},
+		ebmlElement([]byte{0x61, 0xA7},
+			ebmlElement([]byte{0x46, 0x6E}, []byte("font.ttf")),
+			ebmlElement([]byte{0x46, 0x60}, []byte("font/ttf")),
+			ebmlElement([]byte{0x46, 0x5C}, make([]byte, 32)),
+		),
+		ebmlElement([]byte{0x61, 0xA7},
+			ebmlElement([]byte{0x46, 0x6E}, []byte("cover.png")),
+			ebmlElement([]byte{0x46, 0x60}, []byte("image/png")),
+			ebmlElement([]byte{0x46, 0x5C}, cover),
+		),
+	)
+	mkv := testMatroska([]byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	clusterAt := bytes.Index(mkv, []byte{0x1F, 0x43, 0xB6, 0x75})
+	mkv = append(append(append([]byte{}, mkv[:clusterAt]...), attachments...), mkv[clusterAt:]...)
+
+	for name, data := range map[string][]byte{"cover.mov": mov, "cover.mkv": mkv} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		thumb, err := VideoThumbnail(path, VideoMetadata{Width: 1920, Height: 1080})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c := decodeThumbnail(t, thumb); !isRed(c) {
+			t.Errorf("%s: expected embedded cover, got color %v", name, c)
+		}
+	}
+}
+
+func TestVideoThumbnailTitleCard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.mp4")
+	if err := os.WriteFile(path, []byte("not a video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, err := VideoThumbnail(path, VideoMetadata{Width: 1080, Height: 1920})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := img.Bounds(); bounds.Dy() != ThumbnailMaxSide || bounds.Dx() >= bounds.Dy() {
+		t.Errorf("title card should keep portrait aspect, got %v", bounds)
+	}
+}
+
+func TestDecodeCoverRejectsHugeDimensions(t *testing.T) {
+	cover := testCoverPNG(t)
+	if _, err := decodeCover(cover); err != nil {
+		t.Fatalf("decode cover: %v", err)
+	}
+
+	// 修改 IHDR 中的宽高并重新计算校验和
+	huge := append([]byte(nil), cover...)
+	binary.BigEndian.PutUint32(huge[16:], 100000)
+	binary.BigEndian.PutUint32(huge[20:], 100000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := decodeCover(huge); err == nil {
+		t.Error("expected error for huge cover dimensions")
+	}
+}
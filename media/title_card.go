@@ -0,0 +1,65 @@
+package media
+
+import (
+	"hash/fnv"
+	"image"
+	"image/color"
+	"path/filepath"
+)
+
+// titleCardThumbnailer 没有可用画面时生成的标题卡
+// 背景颜色由文件名决定，中间绘制播放图标
+type titleCardThumbnailer struct{}
+
+func (titleCardThumbnailer) Thumbnail(path string, meta VideoMetadata) (image.Image, error) {
+	width, height := ThumbnailMaxSide, ThumbnailMaxSide*9/16
+	if meta.Width > 0 && meta.Height > 0 {
+		if meta.Width >= meta.Height {
+			height = max(1, ThumbnailMaxSide*meta.Height/meta.Width)
+		} else {
+			width, height = max(1, ThumbnailMaxSide*meta.Width/meta.Height), ThumbnailMaxSide
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(filepath.Base(path)))
+	sum := hash.Sum32()
+	top := color.RGBA{R: uint8(sum >> 24), G: uint8(sum >> 16), B: uint8(sum >> 8), A: 0xFF}
+	bottom := color.RGBA{R: top.R / 4, G: top.G / 4, B: top.B / 4, A: 0xFF}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	cx, cy := float64(width)/2, float64(height)/2
+	radius := float64(min(width, height)) / 5
+	for y := 0; y < height; y++ {
+		row := blend(top, bottom, float64(y)/float64(height))
+		for x := 0; x < width; x++ {
+			c := row
+			dx, dy := float64(x)-cx, float64(y)-cy
+			if dx*dx+dy*dy <= radius*radius {
+				c = blend(c, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, 0.35)
+				if inPlayTriangle(dx/radius, dy/radius) {
+					c = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+				}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+// inPlayTriangle 判断以圆心为原点、半径为 1 的坐标是否在朝右的三角形内
+func inPlayTriangle(x, y float64) bool {
+	const left, right, half = -0.3, 0.5, 0.45
+	if x < left || x > right {
+		return false
+	}
+	limit := half * (right - x) / (right - left)
+	return y >= -limit && y <= limit
+}
+
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x)*(1-t) + float64(y)*t)
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 0xFF}
+}
@@ -0,0 +1,128 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedVideo 没有为该扩展名注册元信息解析器
+var ErrUnsupportedVideo = errors.New("unsupported video container")
+
+// VideoMetadata 视频的时长和画面尺寸
+type VideoMetadata struct {
+	Width    int
+	Height   int
+	Duration float64 // 秒，容器中没有记录时为 0
+}
+
+// VideoProber 从某种容器格式中读取视频元信息
+// 实现只读取文件头部的索引结构，不解码视频数据
+type VideoProber interface {
+	Probe(r io.ReadSeeker, size int64) (VideoMetadata, error)
+}
+
+// videoContainer 一种已注册的视频容器
+type videoContainer struct {
+	prober     VideoProber
+	mimeType   string
+	streamable bool // Telegram 客户端可以直接播放，上传时作为视频发送
+}
+
+var (
+	containersMu sync.RWMutex
+	containers   = map[string]videoContainer{}
+)
+
+func init() {
+	RegisterVideoProber(mp4Prober{}, "video/mp4", ".mp4", ".m4v")
+	RegisterVideoProber(movProber{}, "video/quicktime", ".mov")
+	RegisterVideoProber(matroskaProber{}, "video/x-matroska", ".mkv")
+	RegisterVideoProber(matroskaProber{}, "video/webm", ".webm")
+	// Telegram 客户端无法播放 AVI，只读取元信息，仍作为普通文件发送
+	RegisterVideoMetadataProber(aviProber{}, ".avi")
+}
+
+// RegisterVideoProber 为扩展名注册视频元信息解析器，已存在的注册会被覆盖
+// mimeType 为上传该类文件时使用的 MIME 类型
+func RegisterVideoProber(prober VideoProber, mimeType string, exts ...string) {
+	containersMu.Lock()
+	defer containersMu.Unlock()
+	for _, ext := range exts {
+		containers[strings.ToLower(ext)] = videoContainer{prober: prober, mimeType: mimeType, streamable: true}
+	}
+}
+
+// RegisterVideoMetadataProber 为 Telegram 客户端无法播放的容器注册解析器，只用于读取元信息
+func RegisterVideoMetadataProber(prober VideoProber, exts ...string) {
+	containersMu.Lock()
+	defer containersMu.Unlock()
+	for _, ext := range exts {
+		containers[strings.ToLower(ext)] = videoContainer{prober: prober}
+	}
+}
+
+func lookupContainer(path string) (videoContainer, bool) {
+	containersMu.RLock()
+	defer containersMu.RUnlock()
+	container, ok := containers[strings.ToLower(filepath.Ext(path))]
+	return container, ok
+}
+
+// IsVideo 判断文件扩展名是否有已注册的视频解析器
+func IsVideo(path string) bool {
+	_, ok := lookupContainer(path)
+	return ok
+}
+
+// IsStreamableVideo 判断文件是否为 Telegram 客户端可以直接播放的视频
+func IsStreamableVideo(path string) bool {
+	container, ok := lookupContainer(path)
+	return ok && container.streamable
+}
+
+// VideoMimeType 返回可播放视频容器的 MIME 类型，未注册或无法播放时返回空字符串
+func VideoMimeType(path string) string {
+	container, _ := lookupContainer(path)
+	return container.mimeType
+}
+
+// ProbeVideo 按扩展名选择解析器读取视频元信息
+// 文件损坏或缺少视频轨道时返回错误，调用方应按普通文件处理
+func ProbeVideo(path string) (meta VideoMetadata, err error) {
+	container, ok := lookupContainer(path)
+	if !ok {
+		return VideoMetadata{}, fmt.Errorf("%w: %s", ErrUnsupportedVideo, filepath.Ext(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("open file failed: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("stat file failed: %w", err)
+	}
+
+	// 解析器面对的是下载来的任意文件，损坏的文件不应导致程序崩溃
+	defer func() {
+		if r := recover(); r != nil {
+			meta, err = VideoMetadata{}, fmt.Errorf("probe video panicked: %v", r)
+		}
+	}()
+
+	meta, err = container.prober.Probe(file, stat.Size())
+	if err != nil {
+		return VideoMetadata{}, err
+	}
+	if meta.Width <= 0 || meta.Height <= 0 {
+		return VideoMetadata{}, errors.New("video track has no dimensions")
+	}
+	return meta, nil
+}
@@ -0,0 +1,190 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ebmlElement 生成 EBML 元素，长度统一使用 8 字节编码
+func ebmlElement(id []byte, children ...[]byte) []byte {
+	payload := bytes.Join(children, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(payload)))
+	size[0] = 0x01
+	return append(append(append([]byte{}, id...), size...), payload...)
+}
+
+func ebmlFloat(v float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(v))
+	return data
+}
+
+func testMatroska(segmentSize []byte) []byte {
+	info := ebmlElement([]byte{0x15, 0x49, 0xA9, 0x66},
+		ebmlElement([]byte{0x2A, 0xD7, 0xB1}, []byte{0x0F, 0x42, 0x40}),
+		ebmlElement([]byte{0x44, 0x89}, ebmlFloat(90500)),
+	)
+	tracks := ebmlElement([]byte{0x16, 0x54, 0xAE, 0x6B},
+		// 音频轨道在前，应被跳过
+		ebmlElement([]byte{0xAE}, ebmlElement([]byte{0x83}, []byte{2})),
+		ebmlElement([]byte{0xAE},
+			ebmlElement([]byte{0x83}, []byte{1}),
+			ebmlElement([]byte{0xE0},
+				ebmlElement([]byte{0xB0}, []byte{0x07, 0x80}),
+				ebmlElement([]byte{0xBA}, []byte{0x04, 0x38}),
+			),
+		),
+	)
+	cluster := ebmlElement([]byte{0x1F, 0x43, 0xB6, 0x75}, make([]byte, 64))
+
+	file := ebmlElement([]byte{0x1A, 0x45, 0xDF, 0xA3}, []byte("webm"))
+	file = append(file, 0x18, 0x53, 0x80, 0x67)
+	if segmentSize == nil {
+		size := make([]byte, 8)
+		binary.BigEndian.PutUint64(size, uint64(len(info)+len(tracks)+len(cluster)))
+		size[0] = 0x01
+		segmentSize = size
+	}
+	file = append(file, segmentSize...)
+	return bytes.Join([][]byte{file, info, tracks, cluster}, nil)
+}
+
+func movAtom(atomType string, children ...[]byte) []byte {
+	payload := bytes.Join(children, nil)
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, uint32(8+len(payload)))
+	copy(header[4:], atomType)
+	return append(header, payload...)
+}
+
+// testMov 生成包含音频和视频轨道的 MOV 文件，extra 追加到 moov 中
+func testMov(extra ...[]byte) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 600)
+	binary.BigEndian.PutUint32(mvhd[16:], 600*42)
+
+	tkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(tkhd[76:], 1280<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], 720<<16)
+
+	hdlr := func(handler string) []byte {
+		data := make([]byte, 24)
+		copy(data[8:], handler)
+		return movAtom("hdlr", data)
+	}
+
+	moov := movAtom("moov",
+		movAtom("mvhd", mvhd),
+		movAtom("trak", movAtom("tkhd", make([]byte, 84)), movAtom("mdia", hdlr("soun"))),
+		movAtom("trak", movAtom("tkhd", tkhd), movAtom("mdia", hdlr("vide"))),
+		bytes.Join(extra, nil),
+	)
+	return bytes.Join([][]byte{movAtom("ftyp", []byte("qt  ")), movAtom("mdat", make([]byte, 32)), moov}, nil)
+}
+
+func testAvi() []byte {
+	avih := make([]byte, 56)
+	binary.LittleEndian.PutUint32(avih[0:], 40000) // 25fps
+	binary.LittleEndian.PutUint32(avih[16:], 250)
+	binary.LittleEndian.PutUint32(avih[32:], 640)
+	binary.LittleEndian.PutUint32(avih[36:], 480)
+
+	chunk := func(id string, data []byte) []byte {
+		header := make([]byte, 8)
+		copy(header, id)
+		binary.LittleEndian.PutUint32(header[4:], uint32(len(data)))
+		return append(header, data...)
+	}
+	hdrl := chunk("LIST", append([]byte("hdrl"), chunk("avih", avih)...))
+	return chunk("RIFF", append([]byte("AVI "), hdrl...))
+}
+
+func TestVideoProbers(t *testing.T) {
+	unknownSize := []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	tests := []struct {
+		name   string
+		prober VideoProber
+		data   []byte
+		want   VideoMetadata
+	}{
+		{"matroska", matroskaProber{}, testMatroska(nil), VideoMetadata{Width: 1920, Height: 1080, Duration: 90.5}},
+		{"matroska unknown size segment", matroskaProber{}, testMatroska(unknownSize), VideoMetadata{Width: 1920, Height: 1080, Duration: 90.5}},
+		{"mov", movProber{}, testMov(), VideoMetadata{Width: 1280, Height: 720, Duration: 42}},
+		{"avi", aviProber{}, testAvi(), VideoMetadata{Width: 640, Height: 480, Duration: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.prober.Probe(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if err != nil {
+				t.Fatalf("probe failed: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeVideoCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"truncated.mkv": testMatroska(nil)[:40],
+		"garbage.webm":  bytes.Repeat([]byte{0xFF}, 128),
+		"truncated.mov": testMov()[:60],
+		"empty.avi":     nil,
+		"garbage.mp4":   []byte("not a video"),
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if meta, err := ProbeVideo(path); err == nil {
+			t.Errorf("%s: expected error, got %+v", name, meta)
+		}
+	}
+
+	if _, err := ProbeVideo(filepath.Join(dir, "movie.rmvb")); err == nil {
+		t.Error("expected error for unsupported container")
+	}
+}
+
+func TestEBMLReaderRejectsLargeSizes(t *testing.T) {
+	r := newEBMLReader(bytes.NewReader(make([]byte, 16)))
+	if _, err := r.readFloat(1 << 40); err == nil {
+		t.Error("expected error for float size 1<<40")
+	}
+	if _, err := r.read(ebmlMaxReadSize + 1); err == nil {
+		t.Error("expected error for read over the limit")
+	}
+	if r.pos != 0 {
+		t.Errorf("rejected reads should not consume data, pos = %d", r.pos)
+	}
+	if value, err := r.readFloat(8); err != nil || value != 0 {
+		t.Errorf("readFloat(8) = %v, %v", value, err)
+	}
+}
+
+func TestStreamableVideo(t *testing.T) {
+	for path, want := range map[string]bool{
+		"movie.mp4":  true,
+		"movie.MKV":  true,
+		"movie.webm": true,
+		"movie.avi":  false,
+		"movie.txt":  false,
+	} {
+		if got := IsStreamableVideo(path); got != want {
+			t.Errorf("IsStreamableVideo(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !IsVideo("movie.avi") || VideoMimeType("movie.avi") != "" {
+		t.Error("avi should only be probed for metadata")
+	}
+}
@@ -1,69 +1,51 @@
 package telegram
 
 import (
+	"context"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gotd/contrib/bg"
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/tg"
 )
 
-type status string
-
+// 上传文件大小限制：普通帐号 4000 个 512KB 分片（约 2GB），会员帐号 8000 个（约 4GB）
 const (
-	statusIdle = "idle"
-	statusBusy = "busy"
+	uploadLimit        int64 = 4000 * 512 * 1024
+	premiumUploadLimit int64 = 8000 * 512 * 1024
 )
 
-func (s status) IsIdle() bool {
-	return s == statusIdle
-}
-
-func (s status) IsBusy() bool {
-	return s == statusBusy
-}
-
-func (s status) SetBusy() {
-	s = statusBusy
-}
-
-func (s status) SetIdle() {
-	s = statusIdle
-}
-
 type Client struct {
-	mu sync.Mutex
-
 	uuid   string
-	status status
 	client *telegram.Client
 	stop   bg.StopFunc
+
+	inFlight      int          // 正在租用该帐号的任务数量，由 ClientPool 的锁保护
+	cooldownUntil atomic.Int64 // 限流冷却结束时间（UnixNano）
+
+	// 缓存文件频道的 access hash，access hash 每个帐号不同
+	channelMu         sync.Mutex
+	channelID         int64
+	channelAccessHash int64
+
+	// 会员状态只缓存成功的查询，失败时下次调用重新查询
+	premiumMu    sync.Mutex
+	premiumKnown bool
+	premium      bool
 }
 
 func NewClient(uuid string) *Client {
 	client := &Client{
-		uuid:   uuid,
-		status: status(statusIdle),
+		uuid: uuid,
 	}
 
-	invokeMiddleware := NewInvokeMiddleware(
-		func() {
-			client.mu.Lock()
-			client.status.SetBusy()
-		},
-		func() {
-			client.status.SetIdle()
-			client.mu.Unlock()
-		},
-	)
-
 	client.client = telegram.NewClient(AppID, AppHash, telegram.Options{
 		SessionStorage: GetSessionStorage(uuid),
-		Middlewares:    []telegram.Middleware{invokeMiddleware},
-		// Resolver: dcs.Plain(dcs.PlainOptions{
-		// 	Dial: dialer,
-		// }),
+		Resolver:       resolver(),
+		Middlewares:    []telegram.Middleware{newFloodWaitMiddleware(client)},
 	})
 
 	stop, err := bg.Connect(client.client)
@@ -80,14 +62,58 @@ func (c *Client) Stop() {
 	c.stop()
 }
 
-func (c *Client) IsIdle() bool {
-	return c.status.IsIdle()
+func (c *Client) UUID() string {
+	return c.uuid
+}
+
+// CoolDown 帐号被限流，until 之前不再分配任务
+func (c *Client) CoolDown(until time.Time) {
+	for {
+		current := c.cooldownUntil.Load()
+		if until.UnixNano() <= current || c.cooldownUntil.CompareAndSwap(current, until.UnixNano()) {
+			return
+		}
+	}
 }
 
-func (c *Client) IsBusy() bool {
-	return c.status.IsBusy()
+// CooldownUntil 限流冷却结束时间，未被限流时为零值
+func (c *Client) CooldownUntil() time.Time {
+	until := c.cooldownUntil.Load()
+	if until == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, until)
 }
 
 func (c *Client) API() *tg.Client {
 	return c.client.API()
 }
+
+// UploadLimit 返回帐号单个文件的上传大小限制，会员帐号限制更大
+// 查询会员状态失败时本次按普通帐号处理
+func (c *Client) UploadLimit() int64 {
+	c.premiumMu.Lock()
+	defer c.premiumMu.Unlock()
+
+	if !c.premiumKnown {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		users, err := c.API().UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUserSelf{}})
+		if err != nil {
+			log.Println("failed to get self user:", err)
+			return uploadLimit
+		}
+		for _, user := range users {
+			if user, ok := user.(*tg.User); ok {
+				c.premium = user.Premium
+			}
+		}
+		c.premiumKnown = true
+	}
+
+	if c.premium {
+		return premiumUploadLimit
+	}
+	return uploadLimit
+}
@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// PoolStrategy 帐号选择策略
+type PoolStrategy string
+
+const (
+	PoolStrategyRoundRobin  PoolStrategy = "round_robin"  // 轮询
+	PoolStrategyLeastLoaded PoolStrategy = "least_loaded" // 选择正在执行任务最少的帐号
+	PoolStrategyRandom      PoolStrategy = "random"       // 随机
+)
+
+// ErrNoClient 帐号池中没有可用的帐号（没有登录任何帐号）
+var ErrNoClient = errors.New("no telegram client")
+
+// ParsePoolStrategy 解析帐号选择策略，空字符串使用轮询
+func ParsePoolStrategy(s string) (PoolStrategy, error) {
+	switch strategy := PoolStrategy(s); strategy {
+	case "":
+		return PoolStrategyRoundRobin, nil
+	case PoolStrategyRoundRobin, PoolStrategyLeastLoaded, PoolStrategyRandom:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown pool strategy: %q", s)
+	}
+}
+
+// ClientPool 发送帐号池
+// 每个帐号同时最多被 maxInFlight 个任务租用，租用后必须调用 Release 归还
+type ClientPool struct {
+	mu sync.Mutex
+
+	clients     []*Client
+	strategy    PoolStrategy
+	maxInFlight int
+	next        int // 轮询的下一个位置
+
+	// 有帐号归还时关闭并替换，用于唤醒等待中的 Acquire
+	released chan struct{}
+}
+
+func NewClientPool(strategy PoolStrategy, maxInFlight int) *ClientPool {
+	if maxInFlight <= 0 {
+		maxInFlight = 1
+	}
+	return &ClientPool{
+		strategy:    strategy,
+		maxInFlight: maxInFlight,
+		released:    make(chan struct{}),
+	}
+}
+
+// Add 添加帐号
+func (p *ClientPool) Add(client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.clients = append(p.clients, client)
+	p.notify()
+}
+
+// Len 帐号数量
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.clients)
+}
+
+// Acquire 租用一个帐号，所有帐号都在忙或限流冷却时阻塞等待，直到有帐号可用或 ctx 结束
+func (p *ClientPool) Acquire(ctx context.Context) (*Client, error) {
+	for {
+		p.mu.Lock()
+		if len(p.clients) == 0 {
+			p.mu.Unlock()
+			return nil, ErrNoClient
+		}
+		if client := p.pick(); client != nil {
+			client.inFlight++
+			p.mu.Unlock()
+			return client, nil
+		}
+		released := p.released
+		cooldownEnd := p.nextCooldownEnd()
+		p.mu.Unlock()
+
+		// 有空闲帐号在冷却中时，冷却结束后重新选择
+		var cooldown <-chan time.Time
+		var timer *time.Timer
+		if !cooldownEnd.IsZero() {
+			timer = time.NewTimer(time.Until(cooldownEnd))
+			cooldown = timer.C
+		}
+
+		select {
+		case <-released:
+		case <-cooldown:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// TryAcquire 租用一个帐号，没有空闲帐号时返回 nil
+func (p *ClientPool) TryAcquire() *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client := p.pick()
+	if client != nil {
+		client.inFlight++
+	}
+	return client
+}
+
+// Release 归还 Acquire 租用的帐号
+func (p *ClientPool) Release(client *Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client.inFlight > 0 {
+		client.inFlight--
+	}
+	p.notify()
+}
+
+// InFlight 帐号当前被租用的次数
+func (p *ClientPool) InFlight(client *Client) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return client.inFlight
+}
+
+// UploadLimit 所有帐号中最小的单文件上传限制，保证任意帐号都能上传
+func (p *ClientPool) UploadLimit() (int64, error) {
+	p.mu.Lock()
+	clients := append([]*Client(nil), p.clients...)
+	p.mu.Unlock()
+
+	if len(clients) == 0 {
+		return 0, ErrNoClient
+	}
+	limit := clients[0].UploadLimit()
+	for _, client := range clients[1:] {
+		limit = min(limit, client.UploadLimit())
+	}
+	return limit, nil
+}
+
+// Close 停止并移除所有帐号
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, client := range p.clients {
+		client.Stop()
+	}
+	p.clients = nil
+	p.next = 0
+	p.notify()
+}
+
+// notify 唤醒等待中的 Acquire，调用者需持有 p.mu
+func (p *ClientPool) notify() {
+	close(p.released)
+	p.released = make(chan struct{})
+}
+
+// available 帐号是否可以分配新任务：未达到并发上限且不在限流冷却中
+func (p *ClientPool) available(client *Client, now time.Time) bool {
+	return client.inFlight < p.maxInFlight && !now.Before(client.CooldownUntil())
+}
+
+// nextCooldownEnd 未达到并发上限但在冷却中的帐号里最早结束冷却的时间，调用者需持有 p.mu
+func (p *ClientPool) nextCooldownEnd() time.Time {
+	var earliest time.Time
+	for _, client := range p.clients {
+		if client.inFlight >= p.maxInFlight {
+			continue
+		}
+		until := client.CooldownUntil()
+		if earliest.IsZero() || until.Before(earliest) {
+			earliest = until
+		}
+	}
+	return earliest
+}
+
+// pick 按策略选择一个空闲帐号，调用者需持有 p.mu
+func (p *ClientPool) pick() *Client {
+	n := len(p.clients)
+	now := time.Now()
+
+	switch p.strategy {
+	case PoolStrategyLeastLoaded:
+		var picked *Client
+		for _, client := range p.clients {
+			if !p.available(client, now) {
+				continue
+			}
+			if picked == nil || client.inFlight < picked.inFlight {
+				picked = client
+			}
+		}
+		return picked
+
+	case PoolStrategyRandom:
+		available := make([]*Client, 0, n)
+		for _, client := range p.clients {
+			if p.available(client, now) {
+				available = append(available, client)
+			}
+		}
+		if len(available) == 0 {
+			return nil
+		}
+		return available[rand.IntN(len(available))]
+
+	default:
+		for i := 0; i < n; i++ {
+			index := (p.next + i) % n
+			if client := p.clients[index]; p.available(client, now) {
+				p.next = (index + 1) % n
+				return client
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tgerr"
+)
+
+func newTestPool(strategy PoolStrategy, maxInFlight int, n int) (*ClientPool, []*Client) {
+	pool := NewClientPool(strategy, maxInFlight)
+	clients := make([]*Client, n)
+	for i := range clients {
+		clients[i] = &Client{}
+		pool.Add(clients[i])
+	}
+	return pool, clients
+}
+
+func TestClientPoolRoundRobin(t *testing.T) {
+	pool, clients := newTestPool(PoolStrategyRoundRobin, 1, 3)
+
+	for i := 0; i < 3; i++ {
+		client := pool.TryAcquire()
+		if client != clients[i] {
+			t.Fatalf("acquire %d: got wrong client", i)
+		}
+	}
+	if pool.TryAcquire() != nil {
+		t.Fatal("all clients leased, expected nil")
+	}
+
+	// 归还后从下一个位置继续轮询
+	pool.Release(clients[1])
+	if pool.TryAcquire() != clients[1] {
+		t.Fatal("released client should be leased again")
+	}
+}
+
+func TestClientPoolLeastLoaded(t *testing.T) {
+	pool, clients := newTestPool(PoolStrategyLeastLoaded, 2, 2)
+
+	first := pool.TryAcquire()
+	second := pool.TryAcquire()
+	if first == second {
+		t.Fatal("least loaded should spread leases across clients")
+	}
+	pool.Release(clients[1])
+	if pool.TryAcquire() != clients[1] {
+		t.Fatal("expected the idle client")
+	}
+	if pool.InFlight(clients[0]) != 1 || pool.InFlight(clients[1]) != 1 {
+		t.Fatalf("unexpected in-flight counters: %d %d", pool.InFlight(clients[0]), pool.InFlight(clients[1]))
+	}
+}
+
+func TestClientPoolAcquireWaits(t *testing.T) {
+	pool, clients := newTestPool(PoolStrategyRandom, 1, 1)
+
+	if _, err := pool.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 超时前没有帐号归还
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// 等待中的 Acquire 在帐号归还后返回
+	done := make(chan *Client)
+	go func() {
+		client, _ := pool.Acquire(context.Background())
+		done <- client
+	}()
+	time.Sleep(10 * time.Millisecond)
+	pool.Release(clients[0])
+	select {
+	case client := <-done:
+		if client != clients[0] {
+			t.Fatal("got wrong client")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire was not woken by release")
+	}
+
+	if _, err := NewClientPool(PoolStrategyRoundRobin, 1).Acquire(context.Background()); err != ErrNoClient {
+		t.Fatalf("expected ErrNoClient, got %v", err)
+	}
+}
+
+func TestClientPoolCooldown(t *testing.T) {
+	pool, clients := newTestPool(PoolStrategyRoundRobin, 1, 2)
+
+	// 冷却中的帐号不会被分配
+	clients[0].CoolDown(time.Now().Add(50 * time.Millisecond))
+	if pool.TryAcquire() != clients[1] {
+		t.Fatal("expected the healthy client")
+	}
+
+	// 唯一空闲帐号在冷却中时，等待冷却结束
+	start := time.Now()
+	client, err := pool.Acquire(context.Background())
+	if err != nil || client != clients[0] {
+		t.Fatalf("expected cooled down client, got err=%v", err)
+	}
+	if time.Since(start) < 40*time.Millisecond {
+		t.Fatal("acquire returned before cooldown ended")
+	}
+}
+
+func TestFloodWaitDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"FLOOD_WAIT_30":        30 * time.Second,
+		"FLOOD_PREMIUM_WAIT_5": 5 * time.Second,
+		"SLOWMODE_WAIT_10":     10 * time.Second,
+		"MESSAGE_ID_INVALID":   0,
+	}
+	for message, want := range cases {
+		d, ok := floodWaitDuration(tgerr.New(420, message))
+		if d != want || ok != (want > 0) {
+			t.Errorf("%s: got %s %v", message, d, ok)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/gotd/td/telegram"
 )
@@ -37,15 +36,8 @@ func GetSessionStorage(uuid string) *telegram.FileSessionStorage {
 	}
 }
 
+// GetAllSessionUUIDs 返回所有有效会话的 UUID，跳过不是会话的文件
 func GetAllSessionUUIDs() []string {
-	files, err := os.ReadDir(sessionDir)
-	if err != nil {
-		return nil
-	}
-
-	uuids := make([]string, 0, len(files))
-	for _, file := range files {
-		uuids = append(uuids, strings.TrimSuffix(file.Name(), ".json"))
-	}
+	uuids, _ := ScanSessions()
 	return uuids
 }
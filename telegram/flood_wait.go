@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// 帐号限流时最多换帐号重试的次数
+const maxFloodWaitRetries = 3
+
+// FloodWaitError 帐号被限流，在 Until 之前不能再发起请求
+type FloodWaitError struct {
+	Until time.Time
+	Err   error
+}
+
+func (e *FloodWaitError) Error() string {
+	return fmt.Sprintf("flood wait until %s: %v", e.Until.Format(time.TimeOnly), e.Err)
+}
+
+func (e *FloodWaitError) Unwrap() error {
+	return e.Err
+}
+
+// IsFloodWait 判断错误是否为帐号限流
+func IsFloodWait(err error) bool {
+	var floodErr *FloodWaitError
+	return errors.As(err, &floodErr)
+}
+
+// floodWaitDuration 解析 FLOOD_WAIT、FLOOD_PREMIUM_WAIT 和 SLOWMODE_WAIT 错误需要等待的时间
+func floodWaitDuration(err error) (time.Duration, bool) {
+	if d, ok := tgerr.AsFloodWait(err); ok {
+		return d, true
+	}
+	if rpcErr, ok := tgerr.AsType(err, "SLOWMODE_WAIT"); ok {
+		return time.Duration(rpcErr.Argument) * time.Second, true
+	}
+	return 0, false
+}
+
+// floodWaitMiddleware 帐号收到限流错误时进入冷却，冷却期间的请求直接返回 FloodWaitError
+type floodWaitMiddleware struct {
+	client *Client
+}
+
+func newFloodWaitMiddleware(client *Client) *floodWaitMiddleware {
+	return &floodWaitMiddleware{client: client}
+}
+
+func (m *floodWaitMiddleware) Handle(next tg.Invoker) telegram.InvokeFunc {
+	return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+		if until := m.client.CooldownUntil(); time.Now().Before(until) {
+			return &FloodWaitError{Until: until, Err: errors.New("account is cooling down")}
+		}
+
+		err := next.Invoke(ctx, input, output)
+		if d, ok := floodWaitDuration(err); ok {
+			until := time.Now().Add(d)
+			m.client.CoolDown(until)
+			log.Printf("account %s flood wait %s", m.client.uuid, d)
+			return &FloodWaitError{Until: until, Err: err}
+		}
+		return err
+	}
+}
+
+// withGlobalClient 租用帐号执行 fn，帐号被限流时换一个帐号重新执行整个操作
+// 不同帐号的 access hash 不同，因此只能在操作层面重试，不能直接重发原请求
+func withGlobalClient(ctx context.Context, fn func(client *Client) error) error {
+	for attempt := 0; ; attempt++ {
+		client, err := AcquireGlobalClient(ctx)
+		if err != nil {
+			return err
+		}
+		err = fn(client)
+		ReleaseGlobalClient(client)
+
+		if !IsFloodWait(err) || attempt >= maxFloodWaitRetries {
+			return err
+		}
+		log.Printf("account %s is cooling down, retry on another account: %v", client.uuid, err)
+	}
+}
@@ -1,38 +1,52 @@
 package telegram
 
-import "sync"
+import (
+	"context"
+	"log"
+)
 
-var _globalClientMutex sync.Map
+var globalPool = NewClientPool(PoolStrategyRoundRobin, 1)
 
-func LoadGolbalClient() {
-	uuids := GetAllSessionUUIDs()
+// LoadGolbalClient 登录所有保存的帐号并加入全局帐号池
+// maxInFlight 为每个帐号同时执行的发送任务数量
+func LoadGolbalClient(strategy PoolStrategy, maxInFlight int) {
+	globalPool = NewClientPool(strategy, maxInFlight)
+
+	uuids, skipped := ScanSessions()
+	for _, file := range skipped {
+		log.Printf("skip session file %s: %s", file.Name, file.Reason)
+	}
 	for _, uuid := range uuids {
 		client := NewClient(uuid)
-		if client != nil {
-			_globalClientMutex.Store(uuid, client)
+		if client == nil {
+			log.Printf("skip session %s: failed to connect", uuid)
+			continue
 		}
+		globalPool.Add(client)
 	}
 }
 
 func StopAllGlobalClient() {
-	_globalClientMutex.Range(func(key, value any) bool {
-		client := value.(*Client)
-		client.Stop()
-		return true
-	})
+	globalPool.Close()
+}
 
-	_globalClientMutex.Clear()
+// GlobalClientCount 全局帐号池中的帐号数量
+func GlobalClientCount() int {
+	return globalPool.Len()
 }
 
-func GetIdleGlobalClient() *Client {
-	var client *Client = nil
-	_globalClientMutex.Range(func(key, value any) bool {
-		if client == nil && value.(*Client).IsIdle() {
-			client = value.(*Client)
-			return false
-		}
-		return true
-	})
+// AcquireGlobalClient 从全局帐号池租用帐号，所有帐号都在忙时等待
+// 使用完后必须调用 ReleaseGlobalClient
+func AcquireGlobalClient(ctx context.Context) (*Client, error) {
+	return globalPool.Acquire(ctx)
+}
+
+// ReleaseGlobalClient 归还 AcquireGlobalClient 租用的帐号
+func ReleaseGlobalClient(client *Client) {
+	globalPool.Release(client)
+}
 
-	return client
+// UploadLimit 全局帐号池中所有帐号最小的单文件上传限制
+func UploadLimit() (int64, error) {
+	return globalPool.UploadLimit()
 }
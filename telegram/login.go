@@ -8,20 +8,27 @@ import (
 	"github.com/gotd/contrib/bg"
 	"github.com/gotd/td/telegram"
 	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/auth/qrlogin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
 )
 
 var globalClient *telegram.Client
 var stopClient bg.StopFunc // 用于停止客户端连接的函数
 
-func Login(ctx context.Context) {
+// Login 登录新帐号并保存会话，qr 为 true 时使用二维码登录，否则使用手机号和验证码登录
+func Login(ctx context.Context, qr bool) {
 	uuid := uuid.New().String()
 
+	// 二维码被扫描后通过 UpdateLoginToken 通知
+	dispatcher := tg.NewUpdateDispatcher()
+	loggedIn := qrlogin.OnLoginToken(dispatcher)
+
 	client := telegram.NewClient(AppID, AppHash, telegram.Options{
 		// Logger:         logger,
 		SessionStorage: GetSessionStorage(uuid),
-		// Resolver: dcs.Plain(dcs.PlainOptions{
-		// 	Dial: dialer,
-		// }),
+		Resolver:       resolver(),
+		UpdateHandler:  dispatcher,
 	})
 
 	needLogin := !SessionExists(uuid)
@@ -33,6 +40,9 @@ func Login(ctx context.Context) {
 		if err := client.Run(loginCtx, func(ctx context.Context) error {
 			// 进行验证登陆
 			phoneAuth := &terminalAuth{}
+			if qr {
+				return qrLogin(ctx, client, phoneAuth, loggedIn)
+			}
 			flow := auth.NewFlow(phoneAuth, auth.SendCodeOptions{
 				AllowFlashCall: false,
 				CurrentNumber:  false,
@@ -49,3 +59,24 @@ func Login(ctx context.Context) {
 		}
 	}
 }
+
+// qrLogin 显示二维码并等待已登录的设备扫码确认，帐号开启两步验证时再输入密码
+func qrLogin(ctx context.Context, client *telegram.Client, terminal *terminalAuth, loggedIn qrlogin.LoggedIn) error {
+	_, err := client.QR().Auth(ctx, loggedIn, terminal.AcceptLoginToken)
+	if tgerr.Is(err, "SESSION_PASSWORD_NEEDED") {
+		password, err := terminal.Password(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := client.Auth().Password(ctx, password); err != nil {
+			fmt.Printf("认证失败: %v\n", err)
+			return err
+		}
+	} else if err != nil {
+		fmt.Printf("认证失败: %v\n", err)
+		return err
+	}
+
+	fmt.Println("登陆成功")
+	return nil
+}
@@ -1,21 +1,123 @@
 package telegram
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
 	"net"
+	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/gotd/td/telegram/dcs"
 	"golang.org/x/net/proxy"
 )
 
-var dialer = func(ctx context.Context, network, address string) (net.Conn, error) {
-	proxyURL, err := url.Parse("socks5://127.0.0.1:7890")
+// 代理地址，为 nil 时直连
+var proxyURL *url.URL
+
+// SetProxy 设置 MTProto 连接使用的代理，需要在创建客户端之前调用
+func SetProxy(u *url.URL) {
+	proxyURL = u
+}
+
+// resolver 返回按代理设置连接数据中心的 Resolver，未设置代理时返回 nil 使用默认 Resolver
+func resolver() dcs.Resolver {
+	if proxyURL == nil {
+		return nil
+	}
+	return dcs.Plain(dcs.PlainOptions{
+		Dial: dialer(proxyURL),
+	})
+}
+
+// dialer 根据代理协议创建拨号函数
+func dialer(u *url.URL) dcs.DialFunc {
+	switch u.Scheme {
+	case "http", "https":
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialHTTPProxy(ctx, u, network, address)
+		}
+	default:
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			// proxy.FromURL 会读取 URL 中的用户名密码
+			d, err := proxy.FromURL(u, proxy.Direct)
+			if err != nil {
+				return nil, err
+			}
+			if cd, ok := d.(proxy.ContextDialer); ok {
+				return cd.DialContext(ctx, network, address)
+			}
+			return d.Dial(network, address)
+		}
+	}
+}
+
+// dialHTTPProxy 通过 HTTP CONNECT 建立隧道
+func dialHTTPProxy(ctx context.Context, u *url.URL, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, u.Host)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("连接代理失败: %w", err)
+	}
+	if u.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("代理 TLS 握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	// 握手期间遵守 ctx 的截止时间
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
 	}
-	proxy, err := proxy.FromURL(proxyURL, proxy.Direct)
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: http.Header{},
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("发送 CONNECT 请求失败: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return nil, fmt.Errorf("读取 CONNECT 响应失败: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("代理拒绝连接: %s", resp.Status)
 	}
-	return proxy.Dial(network, address)
+
+	// 代理可能在响应之后紧接着发送了数据，需要保留已缓冲的部分
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn 先读取 bufio.Reader 中已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
 }
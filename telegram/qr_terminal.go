@@ -0,0 +1,45 @@
+package telegram
+
+import (
+	"io"
+	"strings"
+
+	"rsc.io/qr"
+)
+
+// 二维码四周的空白模块数，扫码需要足够的静区
+const qrQuietZone = 2
+
+// printQRCode 在终端中打印二维码，每个字符表示上下两个模块
+// 按深色背景的终端绘制：浅色模块用方块字符，深色模块留空
+func printQRCode(w io.Writer, text string) error {
+	code, err := qr.Encode(text, qr.L)
+	if err != nil {
+		return err
+	}
+
+	light := func(x, y int) bool {
+		return !code.Black(x, y)
+	}
+
+	var b strings.Builder
+	for y := -qrQuietZone; y < code.Size+qrQuietZone; y += 2 {
+		for x := -qrQuietZone; x < code.Size+qrQuietZone; x++ {
+			top, bottom := light(x, y), light(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gotd/td/tg"
+)
+
+const (
+	// MaxAlbumSize 一个相册最多包含的图片/视频数量
+	MaxAlbumSize = 10
+	// PhotoSizeLimit 作为图片发送的最大文件大小，超过时作为文件发送
+	PhotoSizeLimit int64 = 10 * 1024 * 1024
+)
+
+// SendCommentAlbum 上传最多 MaxAlbumSize 个图片/视频，作为一个相册发送到频道消息 msgId 的评论区
+// 返回相册中每个文件的评论消息，顺序与 paths 相同
+func SendCommentAlbum(paths []string, msgId int, progress func(UploadProgressParams)) ([]CommentMessage, error) {
+	if len(paths) == 0 || len(paths) > MaxAlbumSize {
+		return nil, fmt.Errorf("album must contain 1 to %d files, got %d", MaxAlbumSize, len(paths))
+	}
+
+	var comments []CommentMessage
+	err := withGlobalClient(context.Background(), func(client *Client) error {
+		channelId, accessHash, err := getInputPeerChannel(client)
+		if err != nil {
+			log.Println("failed to get channel:", err)
+			return err
+		}
+		commonetInputPeerChannel, err := getCommonetInputPeerChannel(client, channelId, accessHash)
+		if err != nil {
+			log.Println("failed to get commonet input peer channel:", err)
+			return err
+		}
+
+		// 相册中的媒体需要先通过 messages.uploadMedia 上传，再引用已上传的图片/文件发送
+		multiMedia := make([]tg.InputSingleMedia, 0, len(paths))
+		for _, path := range paths {
+			inputFile, err := uploadFile(client, path, progress)
+			if err != nil {
+				log.Println("failed to upload file:", err)
+				return err
+			}
+			media, err := uploadMedia(client, commonetInputPeerChannel, fileMedia(client, path, inputFile))
+			if err != nil {
+				log.Println("failed to upload media:", err)
+				return err
+			}
+			multiMedia = append(multiMedia, tg.InputSingleMedia{
+				Media:    media,
+				RandomID: rand.Int64(),
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		update, err := client.API().MessagesSendMultiMedia(ctx, &tg.MessagesSendMultiMediaRequest{
+			Peer: commonetInputPeerChannel,
+			ReplyTo: &tg.InputReplyToMessage{
+				TopMsgID:     msgId,
+				ReplyToMsgID: msgId,
+			},
+			MultiMedia: multiMedia,
+		})
+		if err != nil {
+			log.Println("failed to send multi media:", err)
+			return err
+		}
+
+		chatID := BotAPIChatID(commonetInputPeerChannel)
+		comments = make([]CommentMessage, 0, len(multiMedia))
+		for _, messageID := range sentMessageIDs(update, multiMedia) {
+			comments = append(comments, CommentMessage{ChatID: chatID, MessageID: messageID})
+		}
+		return nil
+	})
+	return comments, err
+}
+
+// uploadMedia 将刚上传的文件转换为可在相册中引用的图片/文件
+func uploadMedia(client *Client, peer tg.InputPeerClass, media tg.InputMediaClass) (tg.InputMediaClass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	uploaded, err := client.API().MessagesUploadMedia(ctx, &tg.MessagesUploadMediaRequest{
+		Peer:  peer,
+		Media: media,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch uploaded := uploaded.(type) {
+	case *tg.MessageMediaPhoto:
+		if photo, ok := uploaded.Photo.(*tg.Photo); ok {
+			return &tg.InputMediaPhoto{ID: photo.AsInput()}, nil
+		}
+	case *tg.MessageMediaDocument:
+		if document, ok := uploaded.Document.(*tg.Document); ok {
+			return &tg.InputMediaDocument{ID: document.AsInput()}, nil
+		}
+	}
+	return nil, errors.New("unexpected uploaded media")
+}
+
+// sentMessageIDs 从发送相册的返回结果中按发送顺序解析消息 ID
+func sentMessageIDs(update tg.UpdatesClass, multiMedia []tg.InputSingleMedia) []int {
+	updates, ok := update.(*tg.Updates)
+	if !ok {
+		return nil
+	}
+
+	idByRandomID := map[int64]int{}
+	for _, update := range updates.Updates {
+		if update, ok := update.(*tg.UpdateMessageID); ok {
+			idByRandomID[update.RandomID] = update.ID
+		}
+	}
+
+	messageIDs := make([]int, 0, len(multiMedia))
+	for _, media := range multiMedia {
+		if id, ok := idByRandomID[media.RandomID]; ok {
+			messageIDs = append(messageIDs, id)
+		}
+	}
+	return messageIDs
+}
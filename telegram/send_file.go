@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"bt-bot/media"
 	"context"
 	"errors"
 	"fmt"
@@ -12,66 +13,72 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Eyevinn/mp4ff/mp4"
 	"github.com/gotd/td/telegram/uploader"
 	"github.com/gotd/td/tg"
 )
 
-const channelUsername = "tgqpXOZ2tzXN"
+// 文件缓存频道用户名（不带 @），启动时由配置文件设置，默认值见 utils 中的默认配置
+var channelUsername string
 
-func SendChannelMessage(text string) (int, error) {
-	client := GetIdleGlobalClient()
-	if client == nil {
-		log.Println("no idle client")
-		return 0, errors.New("no idle client")
-	}
+// SetChannelUsername 设置文件缓存频道用户名
+func SetChannelUsername(username string) {
+	channelUsername = username
+}
 
-	channelId, accessHash, err := getInputPeerChannel(client)
-	if err != nil {
-		log.Println("failed to get channel:", err)
-		return 0, err
-	}
+func SendChannelMessage(text string) (int, error) {
+	var msgId int
+	err := withGlobalClient(context.Background(), func(client *Client) error {
+		channelId, accessHash, err := getInputPeerChannel(client)
+		if err != nil {
+			log.Println("failed to get channel:", err)
+			return err
+		}
 
-	// 发送信息
-	sendMsg := &tg.MessagesSendMessageRequest{
-		Peer: &tg.InputPeerChannel{
-			ChannelID:  channelId,
-			AccessHash: accessHash,
-		},
-		Message:  text,
-		RandomID: rand.Int64(),
-	}
+		// 发送信息
+		sendMsg := &tg.MessagesSendMessageRequest{
+			Peer: &tg.InputPeerChannel{
+				ChannelID:  channelId,
+				AccessHash: accessHash,
+			},
+			Message:  text,
+			RandomID: rand.Int64(),
+		}
 
-	update, err := client.API().MessagesSendMessage(context.TODO(), sendMsg)
+		update, err := client.API().MessagesSendMessage(context.TODO(), sendMsg)
+		if err != nil {
+			log.Println("failed to send message:", err)
+			return err
+		}
+		msgId = sentMessageID(update)
+		return nil
+	})
 	if err != nil {
-		log.Println("failed to send message:", err)
 		return 0, err
 	}
-	msgId := 0
-	if updates, ok := update.(*tg.Updates); ok {
-		for _, update := range updates.Updates {
-			if update, ok := update.(*tg.UpdateMessageID); ok {
-				msgId = update.ID
-				break
-			}
-		}
-	}
 
 	sleepTime := 4 * time.Second
 	for {
 		time.Sleep(sleepTime)
-		msgId, err = getDiscussionMessageId(client, msgId, channelId, accessHash)
+		var discussionMsgId int
+		err := withGlobalClient(context.Background(), func(client *Client) error {
+			channelId, accessHash, err := getInputPeerChannel(client)
+			if err != nil {
+				return err
+			}
+			discussionMsgId, err = getDiscussionMessageId(client, msgId, channelId, accessHash)
+			return err
+		})
 		if err == nil {
-			log.Println("get discussion message id success", msgId)
-			return msgId, nil
+			log.Println("get discussion message id success", discussionMsgId)
+			return discussionMsgId, nil
 		}
 
-		if !(strings.Contains(err.Error(), "FLOOD_WAIT") ||
+		if !(IsFloodWait(err) ||
 			strings.Contains(err.Error(), "MSG_ID_INVALID")) {
 			log.Println("failed to get discussion message id:", err)
 			return 0, err
 		}
-		// FLOOD_WAIT 错误时指数退避，最大60秒
+		// 所有帐号都被限流或讨论消息尚未生成时指数退避，最大60秒
 		if sleepTime < 60*time.Second {
 			sleepTime *= 2
 			if sleepTime > 60*time.Second {
@@ -82,6 +89,14 @@ func SendChannelMessage(text string) (int, error) {
 }
 
 func getInputPeerChannel(client *Client) (channelId int64, accessHash int64, err error) {
+	client.channelMu.Lock()
+	defer client.channelMu.Unlock()
+
+	// 使用缓存，避免每次发送都拉取对话列表
+	if client.channelID != 0 {
+		return client.channelID, client.channelAccessHash, nil
+	}
+
 	// 搜索频道详情
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
@@ -109,6 +124,7 @@ func getInputPeerChannel(client *Client) (channelId int64, accessHash int64, err
 	for _, chat := range chats {
 		if chat, ok := chat.(*tg.Channel); ok {
 			if chat.Username == channelUsername {
+				client.channelID, client.channelAccessHash = chat.ID, chat.AccessHash
 				return chat.ID, chat.AccessHash, nil
 			}
 		}
@@ -170,73 +186,64 @@ func getDiscussionMessageId(client *Client, msgId int, channelId int64, accessHa
 	return 0, errors.New("discussion message not found")
 }
 
-func SendCommentMessage(path string, msgId int) error {
-	// 检查文件是否存在
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil
-	}
-
-	client := GetIdleGlobalClient()
-	if client == nil {
-		log.Println("no idle client")
-		return errors.New("no idle client")
-	}
+// CommentMessage 评论区中已发送的文件消息，ChatID 为 Bot API 格式的讨论组 ID
+type CommentMessage struct {
+	ChatID    int64
+	MessageID int
+}
 
-	inputFile, err := uploadFile(client, path)
+// SendCommentMessage 上传文件到频道消息的评论区，返回评论消息，文件不存在或无法读取时返回错误
+// 超过帐号上传限制的文件拆分为多个分卷，每个分卷单独发送一条评论
+// progress 用于接收上传进度，可以为 nil
+func SendCommentMessage(path string, msgId int, progress func(UploadProgressParams)) ([]CommentMessage, error) {
+	// 检查文件是否存在
+	stat, err := os.Stat(path)
 	if err != nil {
-		log.Println("failed to upload file:", err)
-		return err
+		return nil, err
 	}
 
-	channelId, accessHash, err := getInputPeerChannel(client)
+	// 超过上传限制分卷发送，按所有帐号中最小的限制计算，限流重试时任意帐号都能上传
+	limit, err := globalPool.UploadLimit()
 	if err != nil {
-		log.Println("failed to get channel:", err)
-		return err
+		return nil, err
+	}
+	if stat.Size() > limit {
+		return sendCommentParts(path, stat.Size(), limit, msgId, progress)
 	}
 
-	commonetInputPeerChannel, err := getCommonetInputPeerChannel(client, channelId, accessHash)
-	if err != nil {
-		log.Println("failed to get commonet input peer channel:", err)
+	var comment CommentMessage
+	err = withGlobalClient(context.Background(), func(client *Client) error {
+		inputFile, err := uploadFile(client, path, progress)
+		if err != nil {
+			log.Println("failed to upload file:", err)
+			return err
+		}
+
+		comment, err = sendCommentMedia(client, msgId, fileMedia(client, path, inputFile), "")
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return []CommentMessage{comment}, nil
+}
 
+// fileMedia 根据文件类型生成上传的媒体信息
+func fileMedia(client *Client, path string, inputFile tg.InputFileClass) tg.InputMediaClass {
 	filename := filepath.Base(path)
 	ext := filepath.Ext(path)
-	sendMsg := &tg.MessagesSendMediaRequest{
-		Peer:     commonetInputPeerChannel,
-		RandomID: rand.Int64(),
-		ReplyTo: &tg.InputReplyToMessage{
-			TopMsgID:     msgId,
-			ReplyToMsgID: msgId,
-		},
-	}
 	mimeType := mime.TypeByExtension(ext)
 	if mimeType == "" {
 		mimeType = "application/octet-stream"
 	}
 
 	switch ext {
-	case ".mp4":
-		width, height, duration, err := parseMp4VideoMetadata(path)
-		if err != nil {
-			log.Println("failed to parse mp4 video metadata, using defaults:", err)
-			width, height, duration = 1920, 1080, 60
-		}
-		sendMsg.Media = &tg.InputMediaUploadedDocument{
-			Attributes: []tg.DocumentAttributeClass{
-				&tg.DocumentAttributeFilename{FileName: filename},
-				&tg.DocumentAttributeVideo{
-					SupportsStreaming: true,
-					Duration:          float64(duration),
-					W:                 width,
-					H:                 height,
-				},
-			},
-			File:     inputFile,
-			MimeType: mimeType,
+	case ".jpg", ".jpeg", ".png", ".webp":
+		// 图片作为图片发送，超过图片大小限制时作为文件发送
+		if stat, err := os.Stat(path); err == nil && stat.Size() <= PhotoSizeLimit {
+			return &tg.InputMediaUploadedPhoto{File: inputFile}
 		}
-	default:
-		sendMsg.Media = &tg.InputMediaUploadedDocument{
+		return &tg.InputMediaUploadedDocument{
 			Attributes: []tg.DocumentAttributeClass{
 				&tg.DocumentAttributeFilename{FileName: filename},
 			},
@@ -245,103 +252,184 @@ func SendCommentMessage(path string, msgId int) error {
 		}
 	}
 
-	if _, err = client.API().MessagesSendMedia(context.TODO(), sendMsg); err != nil {
-		log.Println("failed to send message:", err)
-		return err
+	attributes := []tg.DocumentAttributeClass{
+		&tg.DocumentAttributeFilename{FileName: filename},
+	}
+	var thumb tg.InputFileClass
+	if media.IsStreamableVideo(path) {
+		// 视频附带时长和尺寸才能在客户端中直接播放，解析失败时按普通文件发送
+		meta, err := media.ProbeVideo(path)
+		if err != nil {
+			log.Println("failed to probe video metadata, sending as document:", path, err)
+		} else {
+			mimeType = media.VideoMimeType(path)
+			attributes = append(attributes, &tg.DocumentAttributeVideo{
+				SupportsStreaming: true,
+				Duration:          meta.Duration,
+				W:                 meta.Width,
+				H:                 meta.Height,
+			})
+			thumb = uploadThumbnail(client, path, meta)
+		}
+	}
+	return &tg.InputMediaUploadedDocument{
+		Attributes: attributes,
+		File:       inputFile,
+		Thumb:      thumb,
+		MimeType:   mimeType,
 	}
-
-	return nil
 }
 
-func uploadFile(client *Client, path string) (tg.InputFileClass, error) {
-	file, err := os.Open(path)
+// uploadThumbnail 生成并上传视频缩略图，失败时返回 nil，视频照常发送
+func uploadThumbnail(client *Client, path string, meta media.VideoMetadata) tg.InputFileClass {
+	data, err := media.VideoThumbnail(path, meta)
 	if err != nil {
-		return nil, fmt.Errorf("open file failed: %w", err)
+		log.Println("failed to generate video thumbnail:", path, err)
+		return nil
 	}
-	defer file.Close()
 
-	// 上传文件
-	// partSize 设置为 512KB (524288 字节)，这是 Telegram 官方推荐的值
-	// 参考: https://core.telegram.org/api/files#uploading-files
-	// 使用 512KB 可以避免过多的协议开销，并且能够达到最大文件大小限制
-	up := uploader.NewUploader(client.API())
-	up.WithPartSize(524288)
-	up.WithThreads(5)
-	up.WithProgress(&UploadProgress{})
-	return up.FromFile(context.TODO(), file)
-}
-
-type UploadProgress struct{}
-
-func (p *UploadProgress) Chunk(ctx context.Context, state uploader.ProgressState) error {
-	log.Println("upload progress:", state.Uploaded, state.Total)
-	return nil
-}
-
-func SendCommentMessageText(text string, msgId int) error {
-	client := GetIdleGlobalClient()
-	if client == nil {
-		log.Println("no idle client")
-		return errors.New("no idle client")
+	thumb, err := uploader.NewUploader(client.API()).FromBytes(context.TODO(), "thumb.jpg", data)
+	if err != nil {
+		log.Println("failed to upload video thumbnail:", path, err)
+		return nil
 	}
+	return thumb
+}
 
+// sendCommentMedia 发送媒体到频道消息 msgId 的评论区
+func sendCommentMedia(client *Client, msgId int, media tg.InputMediaClass, caption string) (CommentMessage, error) {
 	channelId, accessHash, err := getInputPeerChannel(client)
 	if err != nil {
 		log.Println("failed to get channel:", err)
-		return err
+		return CommentMessage{}, err
 	}
 
 	commonetInputPeerChannel, err := getCommonetInputPeerChannel(client, channelId, accessHash)
 	if err != nil {
 		log.Println("failed to get commonet input peer channel:", err)
-		return err
+		return CommentMessage{}, err
 	}
 
-	sendMsg := &tg.MessagesSendMessageRequest{
+	sendMsg := &tg.MessagesSendMediaRequest{
 		Peer:     commonetInputPeerChannel,
 		RandomID: rand.Int64(),
 		ReplyTo: &tg.InputReplyToMessage{
 			TopMsgID:     msgId,
 			ReplyToMsgID: msgId,
 		},
-		Message: text,
+		Media:   media,
+		Message: caption,
 	}
 
-	if _, err = client.API().MessagesSendMessage(context.TODO(), sendMsg); err != nil {
+	update, err := client.API().MessagesSendMedia(context.TODO(), sendMsg)
+	if err != nil {
 		log.Println("failed to send message:", err)
-		return err
+		return CommentMessage{}, err
 	}
 
-	return nil
+	return CommentMessage{
+		ChatID:    BotAPIChatID(commonetInputPeerChannel),
+		MessageID: sentMessageID(update),
+	}, nil
+}
+
+// sentMessageID 从发送消息的返回结果中解析消息 ID
+func sentMessageID(update tg.UpdatesClass) int {
+	updates, ok := update.(*tg.Updates)
+	if !ok {
+		return 0
+	}
+	for _, update := range updates.Updates {
+		switch update := update.(type) {
+		case *tg.UpdateMessageID:
+			return update.ID
+		case *tg.UpdateNewChannelMessage:
+			if message, ok := update.Message.(*tg.Message); ok {
+				return message.ID
+			}
+		}
+	}
+	return 0
 }
 
-func parseMp4VideoMetadata(filePath string) (width, height int, duration int32, err error) {
-	file, err := os.Open(filePath)
+// BotAPIChatID 将 MTProto 频道/超级群转换为 Bot API 使用的 chat ID（-100 前缀）
+func BotAPIChatID(peer tg.InputPeerClass) int64 {
+	if channel, ok := peer.(*tg.InputPeerChannel); ok {
+		return -1000000000000 - channel.ChannelID
+	}
+	return 0
+}
+
+func uploadFile(client *Client, path string, progress func(UploadProgressParams)) (tg.InputFileClass, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("open file failed: %w", err)
+		return nil, fmt.Errorf("open file failed: %w", err)
 	}
 	defer file.Close()
 
-	mp4File, err := mp4.DecodeFile(file, mp4.WithDecodeMode(mp4.DecModeLazyMdat))
-	if err != nil {
-		return 0, 0, 0, fmt.Errorf("decode mp4 failed: %w", err)
+	// 上传文件
+	// partSize 设置为 512KB (524288 字节)，这是 Telegram 官方推荐的值
+	// 参考: https://core.telegram.org/api/files#uploading-files
+	// 使用 512KB 可以避免过多的协议开销，并且能够达到最大文件大小限制
+	up := uploader.NewUploader(client.API())
+	up.WithPartSize(524288)
+	up.WithThreads(5)
+	up.WithProgress(&UploadProgress{callback: progress})
+	return up.FromFile(context.TODO(), file)
+}
+
+// UploadProgressParams 上传进度
+type UploadProgressParams struct {
+	FileName string
+	Uploaded int64
+	Total    int64
+}
+
+type UploadProgress struct {
+	callback func(UploadProgressParams)
+}
+
+func (p *UploadProgress) Chunk(ctx context.Context, state uploader.ProgressState) error {
+	log.Println("upload progress:", state.Uploaded, state.Total)
+	if p.callback != nil {
+		p.callback(UploadProgressParams{
+			FileName: state.Name,
+			Uploaded: state.Uploaded,
+			Total:    state.Total,
+		})
 	}
+	return nil
+}
 
-	if mvhd := mp4File.Moov.Mvhd; mvhd != nil {
-		timescale := mvhd.Timescale
-		durationInTimescale := mvhd.Duration
-		if timescale > 0 {
-			duration = int32(durationInTimescale / uint64(timescale))
+func SendCommentMessageText(text string, msgId int) error {
+	return withGlobalClient(context.Background(), func(client *Client) error {
+		channelId, accessHash, err := getInputPeerChannel(client)
+		if err != nil {
+			log.Println("failed to get channel:", err)
+			return err
 		}
-	}
 
-	for _, track := range mp4File.Moov.Traks {
-		if track.Mdia.Hdlr.HandlerType == "vide" && track.Tkhd != nil {
-			width = int(track.Tkhd.Width >> 16)
-			height = int(track.Tkhd.Height >> 16)
-			return width, height, duration, nil
+		commonetInputPeerChannel, err := getCommonetInputPeerChannel(client, channelId, accessHash)
+		if err != nil {
+			log.Println("failed to get commonet input peer channel:", err)
+			return err
+		}
+
+		sendMsg := &tg.MessagesSendMessageRequest{
+			Peer:     commonetInputPeerChannel,
+			RandomID: rand.Int64(),
+			ReplyTo: &tg.InputReplyToMessage{
+				TopMsgID:     msgId,
+				ReplyToMsgID: msgId,
+			},
+			Message: text,
 		}
-	}
 
-	return 1920, 1080, 60, fmt.Errorf("no video track found in mp4 file")
+		if _, err = client.API().MessagesSendMessage(context.TODO(), sendMsg); err != nil {
+			log.Println("failed to send message:", err)
+			return err
+		}
+
+		return nil
+	})
 }
@@ -6,15 +6,14 @@ import (
 )
 
 func TestSendFile(t *testing.T) {
-	LoadGolbalClient()
+	LoadGolbalClient(PoolStrategyRoundRobin, 1)
 
-	client := GetIdleGlobalClient()
-	if client == nil {
-		log.Println("no idle client")
+	if GlobalClientCount() == 0 {
+		log.Println("no client")
 		return
 	}
 
-	err := SendCommentMessage("45ddbbb4-27e7-4b0c-8bcb-f8245133ed69.jpeg", 20)
+	_, err := SendCommentMessage("45ddbbb4-27e7-4b0c-8bcb-f8245133ed69.jpeg", 20, nil)
 	if err != nil {
 		log.Println("failed to send comment message:", err)
 		return
@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// ErrSessionUnauthorized 会话已失效（帐号退出登录或会话被终止）
+var ErrSessionUnauthorized = errors.New("session is not authorized")
+
+// SkippedSession sessions 目录中不是有效会话的文件及原因
+type SkippedSession struct {
+	Name   string
+	Reason string
+}
+
+// ScanSessions 扫描会话目录，返回有效会话的 UUID 和被跳过的文件
+func ScanSessions() ([]string, []SkippedSession) {
+	files, err := os.ReadDir(sessionDir)
+	if err != nil {
+		return nil, []SkippedSession{{Name: sessionDir, Reason: err.Error()}}
+	}
+
+	uuids := make([]string, 0, len(files))
+	skipped := make([]SkippedSession, 0)
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			skipped = append(skipped, SkippedSession{Name: name, Reason: "is a directory"})
+			continue
+		}
+		if !strings.HasSuffix(name, ".json") {
+			skipped = append(skipped, SkippedSession{Name: name, Reason: "not a .json file"})
+			continue
+		}
+
+		uuid := strings.TrimSuffix(name, ".json")
+		if _, err := LoadSessionData(uuid); err != nil {
+			skipped = append(skipped, SkippedSession{Name: name, Reason: err.Error()})
+			continue
+		}
+		uuids = append(uuids, uuid)
+	}
+	return uuids, skipped
+}
+
+// LoadSessionData 读取并解析会话文件，会话没有授权密钥时返回错误
+func LoadSessionData(uuid string) (*session.Data, error) {
+	loader := session.Loader{Storage: GetSessionStorage(uuid)}
+	data, err := loader.Load(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+	if len(data.AuthKey) == 0 {
+		return nil, errors.New("invalid session: empty auth key")
+	}
+	return data, nil
+}
+
+// CheckSession 连接 Telegram 检查会话是否仍然有效，返回会话所属的帐号
+func CheckSession(ctx context.Context, uuid string) (*tg.User, error) {
+	if _, err := LoadSessionData(uuid); err != nil {
+		return nil, err
+	}
+
+	client := telegram.NewClient(AppID, AppHash, telegram.Options{
+		SessionStorage: GetSessionStorage(uuid),
+		Resolver:       resolver(),
+	})
+
+	var user *tg.User
+	err := client.Run(ctx, func(ctx context.Context) error {
+		status, err := client.Auth().Status(ctx)
+		if err != nil {
+			return err
+		}
+		if !status.Authorized {
+			return ErrSessionUnauthorized
+		}
+		user = status.User
+		return nil
+	})
+	return user, err
+}
+
+// RemoveSession 删除会话文件
+// 只删除本地文件，该会话在 Telegram 的设备列表中仍然存在，需要在客户端中终止
+func RemoveSession(uuid string) error {
+	if !SessionExists(uuid) {
+		return fmt.Errorf("session %s not found", uuid)
+	}
+	return os.Remove(GetSessionFile(uuid))
+}
+
+// RenameSession 重命名会话，新名称只能包含文件名允许的字符
+func RenameSession(oldUUID string, newUUID string) error {
+	if !SessionExists(oldUUID) {
+		return fmt.Errorf("session %s not found", oldUUID)
+	}
+	if newUUID == "" || newUUID != filepath.Base(newUUID) || strings.HasPrefix(newUUID, ".") {
+		return fmt.Errorf("invalid session name %q", newUUID)
+	}
+	if SessionExists(newUUID) {
+		return fmt.Errorf("session %s already exists", newUUID)
+	}
+	return os.Rename(GetSessionFile(oldUUID), GetSessionFile(newUUID))
+}
+
+// ExportSession 导出会话文件内容，可复制到其他服务器的 sessions 目录使用
+func ExportSession(uuid string, w io.Writer) error {
+	if _, err := LoadSessionData(uuid); err != nil {
+		return err
+	}
+	file, err := os.Open(GetSessionFile(uuid))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
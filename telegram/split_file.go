@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
+)
+
+// filePart 大文件的一个分卷，对应原文件中 [Offset, Offset+Size) 的数据
+type filePart struct {
+	Name   string
+	Offset int64
+	Size   int64
+}
+
+// splitFileParts 按 partSize 将文件拆分为 name.001、name.002 ... 的分卷
+// 分卷可直接用 cat name.* > name 或 7-Zip 合并
+func splitFileParts(path string, size int64, partSize int64) []filePart {
+	filename := filepath.Base(path)
+	parts := make([]filePart, 0, (size+partSize-1)/partSize)
+	for offset := int64(0); offset < size; offset += partSize {
+		parts = append(parts, filePart{
+			Name:   fmt.Sprintf("%s.%03d", filename, len(parts)+1),
+			Offset: offset,
+			Size:   min(partSize, size-offset),
+		})
+	}
+	return parts
+}
+
+// sendCommentParts 将文件按上传限制分卷，每个分卷作为一条评论发送到同一个讨论串
+// 每个分卷单独租用帐号，帐号被限流时只需重新发送当前分卷
+func sendCommentParts(path string, size int64, partSize int64, msgId int, progress func(UploadProgressParams)) ([]CommentMessage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file failed: %w", err)
+	}
+	defer file.Close()
+
+	parts := splitFileParts(path, size, partSize)
+	comments := make([]CommentMessage, 0, len(parts))
+	for i, part := range parts {
+		var comment CommentMessage
+		err := withGlobalClient(context.Background(), func(client *Client) error {
+			// 直接从原文件的对应区间上传，无需在磁盘上生成分卷文件
+			reader := io.NewSectionReader(file, part.Offset, part.Size)
+			inputFile, err := uploadReader(client, part.Name, reader, part.Size, progress)
+			if err != nil {
+				log.Println("failed to upload file part:", part.Name, err)
+				return err
+			}
+
+			media := &tg.InputMediaUploadedDocument{
+				Attributes: []tg.DocumentAttributeClass{
+					&tg.DocumentAttributeFilename{FileName: part.Name},
+				},
+				File:     inputFile,
+				MimeType: "application/octet-stream",
+			}
+			caption := fmt.Sprintf("📦 %s (%d/%d)", filepath.Base(path), i+1, len(parts))
+			comment, err = sendCommentMedia(client, msgId, media, caption)
+			return err
+		})
+		if err != nil {
+			return comments, err
+		}
+		comments = append(comments, comment)
+
+		time.Sleep(2 * time.Second)
+	}
+
+	return comments, nil
+}
+
+func uploadReader(client *Client, name string, reader io.Reader, size int64, progress func(UploadProgressParams)) (tg.InputFileClass, error) {
+	up := uploader.NewUploader(client.API())
+	up.WithPartSize(524288)
+	up.WithThreads(5)
+	up.WithProgress(&UploadProgress{callback: progress})
+	return up.Upload(context.TODO(), uploader.NewUpload(name, reader, size))
+}
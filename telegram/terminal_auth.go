@@ -3,8 +3,11 @@ package telegram
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/gotd/td/telegram/auth"
+	"github.com/gotd/td/telegram/auth/qrlogin"
 	"github.com/gotd/td/tg"
 )
 
@@ -45,6 +48,13 @@ func (a *terminalAuth) SignUp(_ context.Context) (auth.UserInfo, error) {
 	return auth.UserInfo{}, fmt.Errorf("注册暂不支持")
 }
 
-func (a *terminalAuth) AcceptLoginToken(_ context.Context, _ tg.AuthLoginToken) error {
+// AcceptLoginToken 显示二维码登录令牌，令牌过期后会使用新令牌再次调用
+func (a *terminalAuth) AcceptLoginToken(_ context.Context, token qrlogin.Token) error {
+	fmt.Println()
+	if err := printQRCode(os.Stdout, token.URL()); err != nil {
+		return err
+	}
+	fmt.Printf("请在已登录的 Telegram 客户端中打开 设置 > 设备 > 连接桌面设备，扫描二维码（%s 前有效）\n",
+		token.Expires().Local().Format(time.TimeOnly))
 	return nil
 }
@@ -3,49 +3,121 @@ package torrent
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 )
 
+// 下载任务的状态
+const (
+	DownloadTaskStateMetadata    = "metadata"
+	DownloadTaskStateDownloading = "downloading"
+	DownloadTaskStateUploading   = "uploading"
+)
+
+// DownloadTask 正在进行的下载任务，用于列出用户的任务
+type DownloadTask struct {
+	InfoHash       string
+	FileIndex      int
+	UserID         int64
+	FileName       string
+	ChatID         int64 // 进度消息所在的聊天
+	MessageID      int   // 进度消息
+	State          string
+	BytesCompleted int64
+	TotalBytes     int64
+	StartedAt      time.Time
+}
+
+type downloadCancelEntry struct {
+	cancel context.CancelFunc
+	task   DownloadTask
+}
+
 // 用于同步操作 downloadCancelMap 的互斥锁
 var (
 	downloadCancelMapLock sync.Mutex
-	// 保存每个下载任务对应的取消函数
-	downloadCancelMap map[string]context.CancelFunc
+	// 保存每个下载任务对应的取消函数和任务信息
+	downloadCancelMap map[string]*downloadCancelEntry
 )
 
 func init() {
-	downloadCancelMap = make(map[string]context.CancelFunc)
+	downloadCancelMap = make(map[string]*downloadCancelEntry)
+}
+
+func downloadCancelKey(infoHash string, fileIndex int, userId int64) string {
+	return fmt.Sprintf("%s-%d-%d", infoHash, fileIndex, userId)
 }
 
 // 设置一个下载任务的取消函数
-func SetDownloadCancel(infoHash string, fileIndex int, cancel context.CancelFunc) {
+func SetDownloadCancel(task DownloadTask, cancel context.CancelFunc) {
 	downloadCancelMapLock.Lock()
 	defer downloadCancelMapLock.Unlock()
 
-	key := fmt.Sprintf("%s-%d", infoHash, fileIndex)
-	downloadCancelMap[key] = cancel
+	key := downloadCancelKey(task.InfoHash, task.FileIndex, task.UserID)
+	downloadCancelMap[key] = &downloadCancelEntry{cancel: cancel, task: task}
 }
 
 // 移除一个下载任务的取消函数
-func RemoveDownloadCancel(infoHash string, fileIndex int) {
+func RemoveDownloadCancel(infoHash string, fileIndex int, userId int64) {
+	downloadCancelMapLock.Lock()
+	defer downloadCancelMapLock.Unlock()
+
+	key := downloadCancelKey(infoHash, fileIndex, userId)
+	delete(downloadCancelMap, key)
+}
+
+// CancelDownloadTask 调用并移除下载任务的取消函数，上传中的任务已经无法取消，返回是否已取消
+// 停止按钮和任务列表中可能已过时的取消按钮都通过它取消任务
+func CancelDownloadTask(infoHash string, fileIndex int, userId int64) bool {
 	downloadCancelMapLock.Lock()
 	defer downloadCancelMapLock.Unlock()
 
-	key := fmt.Sprintf("%s-%d", infoHash, fileIndex)
+	key := downloadCancelKey(infoHash, fileIndex, userId)
+	entry, ok := downloadCancelMap[key]
+	if !ok || entry.task.State == DownloadTaskStateUploading {
+		return false
+	}
+	entry.cancel()
 	delete(downloadCancelMap, key)
+	return true
 }
 
-// 调用并移除某个下载任务的取消函数，实现任务取消
-func DownloadCancel(infoHash string, fileIndex int) bool {
+// updateDownloadTask 更新下载任务的状态和进度，任务已移除时忽略
+func updateDownloadTask(infoHash string, fileIndex int, userId int64, update func(task *DownloadTask)) {
 	downloadCancelMapLock.Lock()
 	defer downloadCancelMapLock.Unlock()
 
-	key := fmt.Sprintf("%s-%d", infoHash, fileIndex)
-	cancel, ok := downloadCancelMap[key]
-	if ok {
-		cancel()
-		delete(downloadCancelMap, key)
+	if entry, ok := downloadCancelMap[downloadCancelKey(infoHash, fileIndex, userId)]; ok {
+		update(&entry.task)
 	}
+}
 
-	return ok
+// FindDownloadTask 查找用户的一个下载任务
+func FindDownloadTask(infoHash string, fileIndex int, userId int64) (DownloadTask, bool) {
+	downloadCancelMapLock.Lock()
+	defer downloadCancelMapLock.Unlock()
+
+	entry, ok := downloadCancelMap[downloadCancelKey(infoHash, fileIndex, userId)]
+	if !ok {
+		return DownloadTask{}, false
+	}
+	return entry.task, true
+}
+
+// DownloadTasks 用户正在进行的下载任务，按开始时间排序
+func DownloadTasks(userId int64) []DownloadTask {
+	downloadCancelMapLock.Lock()
+	defer downloadCancelMapLock.Unlock()
+
+	var tasks []DownloadTask
+	for _, entry := range downloadCancelMap {
+		if entry.task.UserID == userId {
+			tasks = append(tasks, entry.task)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b DownloadTask) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return tasks
 }
@@ -0,0 +1,69 @@
+package torrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDownloadTasks(t *testing.T) {
+	start := time.Now()
+	tasks := []DownloadTask{
+		{InfoHash: "b", FileIndex: 1, UserID: 1, StartedAt: start.Add(2 * time.Second)},
+		{InfoHash: "a", FileIndex: 0, UserID: 1, StartedAt: start},
+		{InfoHash: "c", FileIndex: -1, UserID: 1, StartedAt: start.Add(time.Second), State: DownloadTaskStateUploading},
+		{InfoHash: "a", FileIndex: 0, UserID: 2, StartedAt: start},
+	}
+	canceled := map[string]bool{}
+	for _, task := range tasks {
+		SetDownloadCancel(task, func() { canceled[downloadCancelKey(task.InfoHash, task.FileIndex, task.UserID)] = true })
+		t.Cleanup(func() { RemoveDownloadCancel(task.InfoHash, task.FileIndex, task.UserID) })
+	}
+
+	// 只列出自己的任务，按开始时间排序
+	got := DownloadTasks(1)
+	if len(got) != 3 || got[0].InfoHash != "a" || got[1].InfoHash != "c" || got[2].InfoHash != "b" {
+		t.Fatalf("DownloadTasks(1) = %+v", got)
+	}
+	if got := DownloadTasks(2); len(got) != 1 || got[0].UserID != 2 {
+		t.Fatalf("DownloadTasks(2) = %+v", got)
+	}
+	if got := DownloadTasks(3); len(got) != 0 {
+		t.Fatalf("DownloadTasks(3) = %+v", got)
+	}
+
+	// 上传中的任务无法取消，其他用户的同名任务不受影响
+	if CancelDownloadTask("c", -1, 1) || canceled[downloadCancelKey("c", -1, 1)] {
+		t.Fatal("uploading task should not be canceled")
+	}
+	if !CancelDownloadTask("a", 0, 1) || !canceled[downloadCancelKey("a", 0, 1)] {
+		t.Fatal("downloading task should be canceled")
+	}
+	if _, ok := FindDownloadTask("a", 0, 2); !ok || len(DownloadTasks(1)) != 2 {
+		t.Fatalf("cancel should only remove the user's own task, tasks = %+v", DownloadTasks(1))
+	}
+}
+
+func TestMagnetTasks(t *testing.T) {
+	start := time.Now()
+	tasks := []MagnetTask{
+		{InfoHash: "b", UserID: 1, StartedAt: start.Add(time.Second)},
+		{InfoHash: "a", UserID: 1, StartedAt: start},
+		{InfoHash: "a", UserID: 2, StartedAt: start},
+	}
+	for _, task := range tasks {
+		SetTorrentCancel(task, func() {})
+		t.Cleanup(func() { RemoveTorrentCancel(task.InfoHash, task.UserID) })
+	}
+
+	got := MagnetTasks(1)
+	if len(got) != 2 || got[0].InfoHash != "a" || got[1].InfoHash != "b" {
+		t.Fatalf("MagnetTasks(1) = %+v", got)
+	}
+	if got := MagnetTasks(2); len(got) != 1 || got[0].UserID != 2 {
+		t.Fatalf("MagnetTasks(2) = %+v", got)
+	}
+
+	if !TorrentCancel("a", 1) || len(MagnetTasks(1)) != 1 || len(MagnetTasks(2)) != 1 {
+		t.Fatalf("cancel should only remove the user's own task")
+	}
+}
@@ -2,108 +2,101 @@ package torrent
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/anacrolix/torrent"
 )
 
-var _downloadMapLock sync.Mutex
-
 type ProgressParams struct {
 	BytesCompleted int64
 	TotalBytes     int64
 	FileName       string
+
+	Speed            int64 // 滑动窗口内的平均下载速度（字节/秒）
+	ActivePeers      int   // 已连接的节点数
+	ConnectedSeeders int   // 已连接的做种节点数
 }
 
 type DownloadParams struct {
-	InfoHash  string
-	FileIndex int
+	InfoHash    string
+	FileIndex   int    // 下载内容的标识，用于取消下载
+	FileIndexes []int  // 需要下载的文件序号
+	FileName    string // 进度中显示的文件名
+	UserID      int64  // 发起下载的用户，同一文件可被多个用户同时下载
+	MetaInfo    []byte // 完整的 .torrent 元信息，存在时跳过磁力链接元信息获取
+
+	ChatID    int64     // 进度消息所在的聊天，用于列出任务
+	MessageID int       // 进度消息
+	StartedAt time.Time // 任务开始时间，零值时使用当前时间
 
 	ProgressCallback func(ProgressParams)
 	CancelCallback   func(t *torrent.Torrent)
 	TimeoutCallback  func(t *torrent.Torrent)
+	StalledCallback  func(t *torrent.Torrent)
 	SuccessCallback  func(t *torrent.Torrent)
 }
 
-func Download(params DownloadParams) {
-	magnetLink := fmt.Sprintf("magnet:?xt=urn:btih:%s", params.InfoHash)
-
+// Download 下载文件，元信息获取失败时返回错误，其余结果通过回调通知
+// 相同 infoHash 的多个下载共享同一个 Torrent，各自拥有独立的进度和结果回调
+func Download(params DownloadParams) error {
 	// 创建下载上下文
 	downloadCtx, downloadCancel := context.WithCancel(context.Background())
-	SetDownloadCancel(params.InfoHash, params.FileIndex, downloadCancel)
-	defer RemoveDownloadCancel(params.InfoHash, params.FileIndex)
-
-	// 解析磁力链接，获取 Torrent 句柄
-	t, err := ParseMagnetLink(downloadCtx, magnetLink)
+	startedAt := params.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	SetDownloadCancel(DownloadTask{
+		InfoHash:  params.InfoHash,
+		FileIndex: params.FileIndex,
+		UserID:    params.UserID,
+		FileName:  params.FileName,
+		ChatID:    params.ChatID,
+		MessageID: params.MessageID,
+		State:     DownloadTaskStateMetadata,
+		StartedAt: startedAt,
+	}, downloadCancel)
+	defer RemoveDownloadCancel(params.InfoHash, params.FileIndex, params.UserID)
+
+	// 获取共享的 Torrent 句柄，有完整元信息时直接添加，否则解析磁力链接
+	st, err := acquireTorrent(downloadCtx, params.InfoHash, params.MetaInfo)
 	if err != nil {
 		log.Println("parse magnet link error", err)
-		return
+		return err
 	}
+	t := st.t
 
-	// 获取总长度
-	totalLength := int64(0)
-
-	// 获取文件列表
+	// 获取需要下载的文件和总长度
 	files := t.Files()
-	filename := ""
+	totalLength := int64(0)
 	var targetFiles []*torrent.File
-	if params.FileIndex == -1 {
-		for i := range files {
-			files[i].SetPriority(torrent.PiecePriorityNormal)
-		}
-		filename = "All files"
-		totalLength = t.Info().TotalLength()
-	} else if params.FileIndex == -2 {
-		for i := range files {
-			if HasImageExtension(files[i].DisplayPath()) {
-				files[i].SetPriority(torrent.PiecePriorityNormal)
-				targetFiles = append(targetFiles, files[i])
-				totalLength += files[i].Length()
-			} else {
-				files[i].SetPriority(torrent.PiecePriorityNone)
-			}
+	var targetIndexes []int
+	for _, index := range params.FileIndexes {
+		if index < 0 || index >= len(files) {
+			continue
 		}
-		filename = "All images"
-	} else if params.FileIndex == -3 {
-		for i := range files {
-			if HasVideoExtension(files[i].DisplayPath()) {
-				files[i].SetPriority(torrent.PiecePriorityNormal)
-				targetFiles = append(targetFiles, files[i])
-				totalLength += files[i].Length()
-			} else {
-				files[i].SetPriority(torrent.PiecePriorityNone)
-			}
-		}
-		filename = "All videos"
-	} else {
-		for i := range files {
-			files[i].SetPriority(torrent.PiecePriorityNone)
-		}
-		targetFile := files[params.FileIndex]
-		targetFile.SetPriority(torrent.PiecePriorityNormal)
-		filename = targetFile.DisplayPath()
-		totalLength = targetFile.Length()
-		targetFiles = append(targetFiles, targetFile)
+		targetFiles = append(targetFiles, files[index])
+		targetIndexes = append(targetIndexes, index)
+		totalLength += files[index].Length()
 	}
-	t.DownloadAll()
+	// 合并所有等待者需要的文件优先级
+	st.wantFiles(targetIndexes)
 
 	// 估计下载时间
 	estimatedTime := estimatedDownloadTime(totalLength)
 	baseCtx, baseCancel := context.WithTimeout(downloadCtx, estimatedTime)
 
-	// 清理资源
+	// 清理资源，最后一个等待者离开时才会 Drop Torrent
 	defer func() {
-		t.Drop()
+		releaseTorrent(params.InfoHash, st, targetIndexes)
 		downloadCancel()
 		baseCancel()
-		delete(downloadCancelMap, magnetLink)
 	}()
 
 	// 下载主循环
+	var sampler speedSampler
+	// 元信息获取完成后才开始计时，解析磁力链接的时间不计入停滞
+	stall := newStallDetector(time.Now(), time.Duration(StallTimeout)*time.Minute)
 	for {
 		select {
 		case <-baseCtx.Done():
@@ -115,28 +108,45 @@ func Download(params DownloadParams) {
 				params.TimeoutCallback(t)
 				log.Println("download all file timeout")
 			}
-			return
+			return nil
 		default:
 			bytesCompleted := int64(0)
-			if params.FileIndex == -1 {
-				bytesCompleted = t.BytesCompleted()
-			} else {
-				for _, file := range targetFiles {
-					bytesCompleted += file.BytesCompleted()
-				}
+			for _, file := range targetFiles {
+				bytesCompleted += file.BytesCompleted()
 			}
 
 			// 查询下载进度
 			if bytesCompleted >= totalLength {
-				// 下载完成
+				// 下载完成，成功回调中上传文件
+				updateDownloadTask(params.InfoHash, params.FileIndex, params.UserID, func(task *DownloadTask) {
+					task.State = DownloadTaskStateUploading
+					task.BytesCompleted, task.TotalBytes = bytesCompleted, totalLength
+				})
 				params.SuccessCallback(t)
-				return
+				return nil
 			}
 			// 调用进度回调
+			now := time.Now()
+			speed := sampler.Add(now, bytesCompleted)
+			stats := t.Stats()
+
+			// 长时间没有节点或没有进度，提前结束下载
+			if stall.Stalled(now, bytesCompleted, stats.ActivePeers) {
+				params.StalledCallback(t)
+				log.Println("download stalled", params.InfoHash, params.FileIndex)
+				return nil
+			}
+			updateDownloadTask(params.InfoHash, params.FileIndex, params.UserID, func(task *DownloadTask) {
+				task.State = DownloadTaskStateDownloading
+				task.BytesCompleted, task.TotalBytes = bytesCompleted, totalLength
+			})
 			params.ProgressCallback(ProgressParams{
-				BytesCompleted: bytesCompleted,
-				TotalBytes:     totalLength,
-				FileName:       filename,
+				BytesCompleted:   bytesCompleted,
+				TotalBytes:       totalLength,
+				FileName:         params.FileName,
+				Speed:            speed,
+				ActivePeers:      stats.ActivePeers,
+				ConnectedSeeders: stats.ConnectedSeeders,
 			})
 			time.Sleep(5 * time.Second) // 每5秒刷新一次进度
 		}
@@ -155,25 +165,3 @@ func estimatedDownloadTime(totalLength int64) time.Duration {
 	}
 	return estimatedTime
 }
-
-func HasImageExtension(path string) bool {
-	return strings.HasSuffix(path, ".jpg") ||
-		strings.HasSuffix(path, ".jpeg") ||
-		strings.HasSuffix(path, ".png") ||
-		strings.HasSuffix(path, ".gif") ||
-		strings.HasSuffix(path, ".webp") ||
-		strings.HasSuffix(path, ".bmp") ||
-		strings.HasSuffix(path, ".tiff") ||
-		strings.HasSuffix(path, ".ico") ||
-		strings.HasSuffix(path, ".svg")
-}
-
-func HasVideoExtension(path string) bool {
-	return strings.HasSuffix(path, ".mp4") ||
-		strings.HasSuffix(path, ".avi") ||
-		strings.HasSuffix(path, ".mkv") ||
-		strings.HasSuffix(path, ".mov") ||
-		strings.HasSuffix(path, ".wmv") ||
-		strings.HasSuffix(path, ".flv") ||
-		strings.HasSuffix(path, ".webm")
-}
@@ -0,0 +1,33 @@
+package torrent
+
+import "time"
+
+// 计算下载速度使用的滑动窗口
+const speedWindow = 30 * time.Second
+
+type speedSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// speedSampler 按滑动窗口计算平均下载速度，避免单次采样波动过大
+type speedSampler struct {
+	samples []speedSample
+}
+
+// Add 记录一次已完成字节数，返回窗口内的平均速度（字节/秒）
+func (s *speedSampler) Add(at time.Time, bytes int64) int64 {
+	s.samples = append(s.samples, speedSample{at: at, bytes: bytes})
+
+	// 移除窗口外的采样，至少保留两个用于计算
+	for len(s.samples) > 2 && at.Sub(s.samples[0].at) > speedWindow {
+		s.samples = s.samples[1:]
+	}
+
+	first := s.samples[0]
+	elapsed := at.Sub(first.at).Seconds()
+	if elapsed <= 0 || bytes <= first.bytes {
+		return 0
+	}
+	return int64(float64(bytes-first.bytes) / elapsed)
+}
@@ -0,0 +1,169 @@
+package torrent
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+// 文件分类名称
+const (
+	FileCategoryVideo       = "video"
+	FileCategoryImage       = "image"
+	FileCategoryAudio       = "audio"
+	FileCategorySubtitle    = "subtitle"
+	FileCategoryArchive     = "archive"
+	FileCategoryDocument    = "document"
+	FileCategoryApplication = "application"
+)
+
+// 没有分类的文件使用的 emoji
+const defaultFileEmoji = "📄"
+
+// fileCategory 一类文件的扩展名，每个扩展名可以有自己的 emoji
+type fileCategory struct {
+	name       string
+	emoji      string
+	extensions map[string]string
+}
+
+// 扩展名分类表，扩展名统一保存为小写并带 "."
+var (
+	fileCategories      []*fileCategory
+	extensionCategories = make(map[string]*fileCategory)
+	fileCategoriesMutex sync.RWMutex
+)
+
+func init() {
+	RegisterFileCategory(FileCategoryVideo, "🎬", map[string]string{
+		".mp4": "🎬", ".m4v": "🎬", ".mkv": "🎥", ".avi": "📽️", ".mov": "🎞️",
+		".wmv": "", ".flv": "", ".webm": "", ".ts": "📼", ".m2ts": "📼", ".rmvb": "",
+	})
+	RegisterFileCategory(FileCategoryImage, "🖼️", map[string]string{
+		".jpg": "🖼️", ".jpeg": "🖼️", ".png": "📸", ".gif": "🎞️", ".webp": "🌆",
+		".bmp": "🖼️", ".tiff": "", ".ico": "", ".svg": "",
+	})
+	RegisterFileCategory(FileCategoryAudio, "🎵", map[string]string{
+		".mp3": "🎵", ".flac": "🎶", ".wav": "🔊", ".ape": "🎼", ".aac": "🎧",
+		".ogg": "🎶", ".m4a": "🎧", ".opus": "", ".dsf": "", ".wma": "",
+	})
+	RegisterFileCategory(FileCategorySubtitle, "💬", map[string]string{
+		".srt": "", ".ass": "", ".ssa": "", ".vtt": "", ".sub": "", ".idx": "", ".sup": "",
+	})
+	RegisterFileCategory(FileCategoryArchive, "📦", map[string]string{
+		".zip": "🗜️", ".rar": "🗂️", ".7z": "📦", ".tar": "📦", ".gz": "🗄️",
+		".bz2": "", ".xz": "", ".zst": "",
+	})
+	RegisterFileCategory(FileCategoryDocument, "📄", map[string]string{
+		".pdf": "📑", ".epub": "📚", ".mobi": "📚", ".txt": "📄", ".nfo": "📄", ".md": "📄",
+		".doc": "📝", ".docx": "📝", ".ppt": "📊", ".pptx": "📊", ".xls": "📈", ".xlsx": "📈",
+	})
+	RegisterFileCategory(FileCategoryApplication, "🖥️", map[string]string{
+		".apk": "🤖", ".exe": "🖥️", ".msi": "🖥️", ".dmg": "💿", ".iso": "💿", ".torrent": "🧲",
+	})
+}
+
+// RegisterFileCategory 注册或扩展一个文件分类，扩展名不区分大小写
+// extensions 为扩展名到 emoji 的映射，emoji 为空时使用分类的 emoji
+// 已属于其他分类的扩展名会移到新的分类
+func RegisterFileCategory(name string, emoji string, extensions map[string]string) {
+	fileCategoriesMutex.Lock()
+	defer fileCategoriesMutex.Unlock()
+
+	var category *fileCategory
+	for _, c := range fileCategories {
+		if c.name == name {
+			category = c
+			break
+		}
+	}
+	if category == nil {
+		category = &fileCategory{name: name, extensions: make(map[string]string)}
+		fileCategories = append(fileCategories, category)
+	}
+	if emoji != "" {
+		category.emoji = emoji
+	}
+
+	for ext, extEmoji := range extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if old, ok := extensionCategories[ext]; ok && old != category {
+			delete(old.extensions, ext)
+		}
+		category.extensions[ext] = extEmoji
+		extensionCategories[ext] = category
+	}
+}
+
+// FileCategories 已注册的分类名称，按注册顺序
+func FileCategories() []string {
+	fileCategoriesMutex.RLock()
+	defer fileCategoriesMutex.RUnlock()
+
+	names := make([]string, 0, len(fileCategories))
+	for _, category := range fileCategories {
+		names = append(names, category.name)
+	}
+	return names
+}
+
+// IsFileCategory 判断是否为已注册的分类名称
+func IsFileCategory(name string) bool {
+	fileCategoriesMutex.RLock()
+	defer fileCategoriesMutex.RUnlock()
+
+	for _, category := range fileCategories {
+		if category.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FileExtension 小写的文件扩展名，带 "."
+func FileExtension(filePath string) string {
+	return strings.ToLower(path.Ext(filePath))
+}
+
+// FileCategoryOf 文件所属的分类，没有分类时返回空字符串
+func FileCategoryOf(filePath string) string {
+	fileCategoriesMutex.RLock()
+	defer fileCategoriesMutex.RUnlock()
+
+	if category, ok := extensionCategories[FileExtension(filePath)]; ok {
+		return category.name
+	}
+	return ""
+}
+
+// HasFileCategory 判断文件是否属于指定分类
+func HasFileCategory(filePath string, name string) bool {
+	return name != "" && FileCategoryOf(filePath) == name
+}
+
+// FileEmoji 文件扩展名对应的 emoji，没有分类时返回 📄
+func FileEmoji(filePath string) string {
+	fileCategoriesMutex.RLock()
+	defer fileCategoriesMutex.RUnlock()
+
+	ext := FileExtension(filePath)
+	category, ok := extensionCategories[ext]
+	if !ok {
+		return defaultFileEmoji
+	}
+	if emoji := category.extensions[ext]; emoji != "" {
+		return emoji
+	}
+	return category.emoji
+}
+
+func HasImageExtension(path string) bool {
+	return HasFileCategory(path, FileCategoryImage)
+}
+
+func HasVideoExtension(path string) bool {
+	return HasFileCategory(path, FileCategoryVideo)
+}
@@ -0,0 +1,33 @@
+package torrent
+
+import "time"
+
+// stallDetector 检测下载是否停滞：在 timeout 时间内一直没有节点，或者没有任何下载进度
+type stallDetector struct {
+	timeout time.Duration
+
+	lastBytes    int64
+	lastProgress time.Time // 最近一次有下载进度的时间
+	lastPeer     time.Time // 最近一次有连接节点的时间
+}
+
+func newStallDetector(now time.Time, timeout time.Duration) *stallDetector {
+	return &stallDetector{
+		timeout:      timeout,
+		lastProgress: now,
+		lastPeer:     now,
+	}
+}
+
+// Stalled 记录本次采样，返回下载是否已停滞
+func (d *stallDetector) Stalled(now time.Time, bytesCompleted int64, activePeers int) bool {
+	if bytesCompleted > d.lastBytes {
+		d.lastBytes = bytesCompleted
+		d.lastProgress = now
+	}
+	if activePeers > 0 {
+		d.lastPeer = now
+	}
+
+	return now.Sub(d.lastProgress) > d.timeout || now.Sub(d.lastPeer) > d.timeout
+}
@@ -0,0 +1,85 @@
+package torrent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStallDetector(t *testing.T) {
+	type sample struct {
+		minute  int
+		bytes   int64
+		peers   int
+		stalled bool
+	}
+	tests := []struct {
+		name    string
+		created int // 元信息获取完成、创建检测器的时间（分钟）
+		samples []sample
+	}{
+		{
+			name: "no peers for the timeout",
+			samples: []sample{
+				{minute: 5, bytes: 0, peers: 0},
+				{minute: 20, bytes: 0, peers: 0},
+				{minute: 21, bytes: 0, peers: 0, stalled: true},
+			},
+		},
+		{
+			name: "peers without progress",
+			samples: []sample{
+				{minute: 10, bytes: 0, peers: 3},
+				{minute: 21, bytes: 0, peers: 3, stalled: true},
+			},
+		},
+		{
+			name: "progress resets the timer",
+			samples: []sample{
+				{minute: 15, bytes: 100, peers: 2},
+				{minute: 30, bytes: 200, peers: 2},
+				{minute: 45, bytes: 300, peers: 2},
+				{minute: 66, bytes: 300, peers: 2, stalled: true},
+			},
+		},
+		{
+			name: "progress without peers still stalls",
+			samples: []sample{
+				{minute: 10, bytes: 100, peers: 0},
+				{minute: 21, bytes: 200, peers: 0, stalled: true},
+			},
+		},
+		{
+			name: "peers coming back reset the timer",
+			samples: []sample{
+				{minute: 15, bytes: 100, peers: 0},
+				{minute: 19, bytes: 200, peers: 1},
+				{minute: 30, bytes: 300, peers: 0},
+				{minute: 39, bytes: 400, peers: 0},
+				{minute: 40, bytes: 500, peers: 0, stalled: true},
+			},
+		},
+		{
+			// 获取元信息用了 30 分钟，这段时间不计入停滞
+			name:    "magnet phase excluded",
+			created: 30,
+			samples: []sample{
+				{minute: 31, bytes: 0, peers: 0},
+				{minute: 50, bytes: 0, peers: 0},
+				{minute: 51, bytes: 0, peers: 0, stalled: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			detector := newStallDetector(start.Add(time.Duration(test.created)*time.Minute), 20*time.Minute)
+			for _, s := range test.samples {
+				now := start.Add(time.Duration(s.minute) * time.Minute)
+				if got := detector.Stalled(now, s.bytes, s.peers); got != s.stalled {
+					t.Fatalf("minute %d: stalled = %v, want %v", s.minute, got, s.stalled)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package torrent
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,11 +12,18 @@ import (
 	"time"
 
 	"github.com/anacrolix/torrent"
+	"github.com/anacrolix/torrent/metainfo"
 )
 
+// 以下配置在 InitTorrentClient 之前由配置文件设置
 var (
+	// 磁力链接元信息获取超时（分钟）
 	MagnetTimeout int = 5
 
+	// 下载停滞超时（分钟），超过该时间没有节点或没有下载进度则提前结束下载
+	StallTimeout int = 20
+
+	// 下载目录
 	DownloadDir string = "downloads"
 
 	globalClientMutex sync.Mutex
@@ -23,14 +31,14 @@ var (
 )
 
 func init() {
-	downloadCancelMap = make(map[string]context.CancelFunc)
+	downloadCancelMap = make(map[string]*downloadCancelEntry)
+}
 
+func InitTorrentClient(debug bool) error {
 	if err := os.MkdirAll(DownloadDir, 0755); err != nil {
-		log.Println("创建下载目录失败: ", err)
+		return fmt.Errorf("创建下载目录失败: %w", err)
 	}
-}
 
-func InitTorrentClient(debug bool) error {
 	cfg := torrent.NewDefaultClientConfig()
 	cfg.DataDir = DownloadDir
 	cfg.Debug = debug
@@ -123,3 +131,36 @@ func ParseMagnetLink(ctx context.Context, magnet string) (*torrent.Torrent, erro
 	return t, nil
 
 }
+
+// LoadTorrentFile 解析 .torrent 文件内容
+func LoadTorrentFile(data []byte) (*metainfo.MetaInfo, *metainfo.Info, error) {
+	mi, err := metainfo.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := mi.UnmarshalInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	return mi, &info, nil
+}
+
+// AddTorrentMetaInfo 使用完整的元信息添加 torrent，元信息已知，无需等待 DHT 获取
+func AddTorrentMetaInfo(data []byte) (*torrent.Torrent, error) {
+	mi, _, err := LoadTorrentFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := globalClient.AddTorrent(mi)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Info() == nil {
+		t.Drop()
+		return nil, errors.New("get torrent info failed")
+	}
+
+	return t, nil
+}
@@ -3,27 +3,43 @@ package torrent
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"time"
 )
 
+// MagnetTask 正在进行的磁力链接解析，用于列出用户的任务
+type MagnetTask struct {
+	InfoHash  string
+	UserID    int64
+	ChatID    int64 // 解析中消息所在的聊天
+	MessageID int   // 解析中消息
+	StartedAt time.Time
+}
+
+type torrentCancelEntry struct {
+	cancel context.CancelFunc
+	task   MagnetTask
+}
+
 // 用于同步操作 torrentCancelMap 的互斥锁
 var (
 	torrentCancelMapLock sync.Mutex
-	// 保存每个磁力链接对应的取消函数
-	torrentCancelMap map[string]context.CancelFunc
+	// 保存每个磁力链接对应的取消函数和任务信息
+	torrentCancelMap map[string]*torrentCancelEntry
 )
 
 func init() {
-	torrentCancelMap = make(map[string]context.CancelFunc)
+	torrentCancelMap = make(map[string]*torrentCancelEntry)
 }
 
 // 设置一个磁力链接的取消函数
-func SetTorrentCancel(magnet string, userId int64, cancel context.CancelFunc) {
+func SetTorrentCancel(task MagnetTask, cancel context.CancelFunc) {
 	torrentCancelMapLock.Lock()
 	defer torrentCancelMapLock.Unlock()
 
-	key := fmt.Sprintf("%s-%d", magnet, userId)
-	torrentCancelMap[key] = cancel
+	key := fmt.Sprintf("%s-%d", task.InfoHash, task.UserID)
+	torrentCancelMap[key] = &torrentCancelEntry{cancel: cancel, task: task}
 }
 
 // 移除一个磁力链接的取消函数
@@ -41,11 +57,40 @@ func TorrentCancel(magnet string, userId int64) bool {
 	defer torrentCancelMapLock.Unlock()
 
 	key := fmt.Sprintf("%s-%d", magnet, userId)
-	cancel, ok := torrentCancelMap[key]
+	entry, ok := torrentCancelMap[key]
 	if ok {
-		cancel()
+		entry.cancel()
 		delete(torrentCancelMap, key)
 	}
 
 	return ok
 }
+
+// FindMagnetTask 查找用户的一个解析任务
+func FindMagnetTask(magnet string, userId int64) (MagnetTask, bool) {
+	torrentCancelMapLock.Lock()
+	defer torrentCancelMapLock.Unlock()
+
+	entry, ok := torrentCancelMap[fmt.Sprintf("%s-%d", magnet, userId)]
+	if !ok {
+		return MagnetTask{}, false
+	}
+	return entry.task, true
+}
+
+// MagnetTasks 用户正在进行的解析任务，按开始时间排序
+func MagnetTasks(userId int64) []MagnetTask {
+	torrentCancelMapLock.Lock()
+	defer torrentCancelMapLock.Unlock()
+
+	var tasks []MagnetTask
+	for _, entry := range torrentCancelMap {
+		if entry.task.UserID == userId {
+			tasks = append(tasks, entry.task)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b MagnetTask) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return tasks
+}
@@ -0,0 +1,174 @@
+package torrent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/anacrolix/torrent"
+)
+
+// sharedTorrent 多个下载任务共享的同一个 Torrent 句柄
+type sharedTorrent struct {
+	ready chan struct{} // 元信息获取完成后关闭
+	t     *torrent.Torrent
+	err   error
+
+	refs    int         // 正在使用该 Torrent 的等待者数量
+	wanted  map[int]int // 文件索引 -> 需要该文件的等待者数量
+	cleanup []string    // Torrent 释放后需要删除的文件
+}
+
+// 用于同步操作 torrentRegistry 的互斥锁
+var (
+	torrentRegistryLock sync.Mutex
+	// 按 infoHash 保存共享的 Torrent，相同 infoHash 只添加一次到 globalClient
+	torrentRegistry = map[string]*sharedTorrent{}
+)
+
+// acquireTorrent 获取（或创建）infoHash 对应的共享 Torrent，并增加引用计数
+// 调用者使用完后必须调用 releaseTorrent
+func acquireTorrent(ctx context.Context, infoHash string, metaInfo []byte) (*sharedTorrent, error) {
+	torrentRegistryLock.Lock()
+	st, ok := torrentRegistry[infoHash]
+	if !ok {
+		st = &sharedTorrent{
+			ready:  make(chan struct{}),
+			wanted: map[int]int{},
+		}
+		torrentRegistry[infoHash] = st
+		go st.load(infoHash, metaInfo)
+	}
+	st.refs++
+	torrentRegistryLock.Unlock()
+
+	select {
+	case <-st.ready:
+	case <-ctx.Done():
+		releaseTorrent(infoHash, st, nil)
+		return nil, ctx.Err()
+	}
+
+	if st.err != nil {
+		releaseTorrent(infoHash, st, nil)
+		return nil, st.err
+	}
+
+	return st, nil
+}
+
+// load 获取元信息，由第一个等待者触发，不受单个等待者取消的影响
+func (st *sharedTorrent) load(infoHash string, metaInfo []byte) {
+	defer close(st.ready)
+
+	var t *torrent.Torrent
+	var err error
+	if len(metaInfo) > 0 {
+		t, err = AddTorrentMetaInfo(metaInfo)
+	} else {
+		magnetLink := fmt.Sprintf("magnet:?xt=urn:btih:%s", infoHash)
+		t, err = ParseMagnetLink(context.Background(), magnetLink)
+	}
+
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+
+	st.t, st.err = t, err
+	if err != nil {
+		// 获取失败，移除记录以便后续请求重新获取
+		if torrentRegistry[infoHash] == st {
+			delete(torrentRegistry, infoHash)
+		}
+		return
+	}
+
+	// 获取期间所有等待者都已离开
+	if st.refs == 0 {
+		if torrentRegistry[infoHash] == st {
+			delete(torrentRegistry, infoHash)
+		}
+		t.Drop()
+	}
+}
+
+// wantFiles 登记等待者需要的文件，并合并所有等待者的文件优先级
+func (st *sharedTorrent) wantFiles(indexes []int) {
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+
+	for _, index := range indexes {
+		st.wanted[index]++
+	}
+	st.updatePriorities()
+}
+
+// updatePriorities 有等待者需要的文件设为正常优先级，其余不下载
+// 调用者需持有 torrentRegistryLock
+func (st *sharedTorrent) updatePriorities() {
+	for index, file := range st.t.Files() {
+		if st.wanted[index] > 0 {
+			file.SetPriority(torrent.PiecePriorityNormal)
+		} else {
+			file.SetPriority(torrent.PiecePriorityNone)
+		}
+	}
+}
+
+// releaseTorrent 等待者离开，取消登记的文件并减少引用计数
+// 最后一个等待者离开时 Drop Torrent 并删除待清理的文件
+func releaseTorrent(infoHash string, st *sharedTorrent, indexes []int) {
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+
+	st.refs--
+	for _, index := range indexes {
+		st.wanted[index]--
+		if st.wanted[index] <= 0 {
+			delete(st.wanted, index)
+		}
+	}
+
+	if st.refs > 0 {
+		if st.t != nil {
+			st.updatePriorities()
+		}
+		return
+	}
+
+	// 元信息仍在获取中，由 load 完成后清理
+	if st.t == nil && st.err == nil {
+		return
+	}
+
+	if torrentRegistry[infoHash] == st {
+		delete(torrentRegistry, infoHash)
+	}
+	if st.t != nil {
+		st.t.Drop()
+	}
+	removeFiles(st.cleanup)
+}
+
+// DeleteFilesAfterRelease 在共享 Torrent 的最后一个等待者离开后删除文件
+// 没有等待者时立即删除
+func DeleteFilesAfterRelease(infoHash string, paths []string) {
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+
+	st, ok := torrentRegistry[infoHash]
+	if !ok {
+		removeFiles(paths)
+		return
+	}
+	st.cleanup = append(st.cleanup, paths...)
+}
+
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Println("delete download file error", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package torrent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireTorrentLoadError(t *testing.T) {
+	if _, err := acquireTorrent(context.Background(), "invalid", []byte("not a torrent")); err == nil {
+		t.Fatal("invalid meta info should fail")
+	}
+
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+	if _, ok := torrentRegistry["invalid"]; ok {
+		t.Fatal("failed torrent should be removed from the registry")
+	}
+}
+
+func TestDeleteFilesAfterRelease(t *testing.T) {
+	dir := t.TempDir()
+	newFile := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	// 没有等待者时立即删除
+	unused := newFile("unused")
+	DeleteFilesAfterRelease("unused", []string{unused})
+	if exists(unused) {
+		t.Fatal("file without waiters should be deleted immediately")
+	}
+
+	// 两个等待者共享同一个条目，最后一个离开时才删除（用已结束加载的条目代替真实的 Torrent）
+	ready := make(chan struct{})
+	close(ready)
+	st := &sharedTorrent{ready: ready, err: errors.New("loaded"), refs: 2, wanted: map[int]int{0: 2}}
+	torrentRegistryLock.Lock()
+	torrentRegistry["shared"] = st
+	torrentRegistryLock.Unlock()
+
+	shared := newFile("shared")
+	DeleteFilesAfterRelease("shared", []string{shared})
+	releaseTorrent("shared", st, []int{0})
+	if !exists(shared) || st.wanted[0] != 1 {
+		t.Fatalf("file should be kept while a waiter remains, wanted = %v", st.wanted)
+	}
+
+	releaseTorrent("shared", st, []int{0})
+	if exists(shared) {
+		t.Fatal("file should be deleted after the last waiter leaves")
+	}
+	torrentRegistryLock.Lock()
+	defer torrentRegistryLock.Unlock()
+	if _, ok := torrentRegistry["shared"]; ok || len(st.wanted) != 0 {
+		t.Fatalf("released torrent should be removed, wanted = %v", st.wanted)
+	}
+}
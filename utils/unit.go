@@ -1,6 +1,11 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 func FormatPercentage(completed, total int64) string {
 	if completed == 0 || total == 0 {
@@ -22,3 +27,44 @@ func FormatBytesToSizeString(size int64) string {
 	units := []string{"K", "M", "G", "T"}
 	return fmt.Sprintf("%.2f %s", float64(size)/float64(div), units[exp])
 }
+
+// ParseSizeString 解析 100MB、1.5G、512 K 这样的大小，单位与 FormatBytesToSizeString 相同按 1024 换算，不区分大小写
+func ParseSizeString(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	s = strings.TrimSuffix(s, "B")
+	multiplier := int64(1)
+	for i, unit := range []string{"K", "M", "G", "T"} {
+		if strings.HasSuffix(s, unit) {
+			multiplier = int64(1) << (10 * (i + 1))
+			s = strings.TrimSuffix(s, unit)
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size: %s", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// FormatSpeed 格式化传输速度，如 1.25 M/s
+func FormatSpeed(bytesPerSecond int64) string {
+	return FormatBytesToSizeString(bytesPerSecond) + "/s"
+}
+
+// FormatDuration 格式化时长为 时:分:秒
+func FormatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
+
+// FormatETA 根据剩余字节数和速度估算剩余时间，速度为 0 时无法估算
+func FormatETA(remaining int64, bytesPerSecond int64) string {
+	if bytesPerSecond <= 0 {
+		return "--:--:--"
+	}
+	return FormatDuration(time.Duration(remaining/bytesPerSecond) * time.Second)
+}